@@ -0,0 +1,109 @@
+package negotiator
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	headerAcceptPost  = "Accept-Post"
+	headerAcceptPatch = "Accept-Patch"
+)
+
+// AcceptedTypes are the media types a resource accepts in request bodies.
+type AcceptedTypes struct {
+	// Post are the media ranges accepted by POST, advertised in the HTTP
+	// Accept-Post header. If empty, POST requests aren't checked.
+	Post []string
+	// Patch are the media ranges accepted by PATCH, advertised in the HTTP
+	// Accept-Patch header as defined in RFC 5789. If empty, PATCH requests
+	// aren't checked.
+	Patch []string
+}
+
+// SetHeaders sets the HTTP Accept-Post and Accept-Patch headers of a to h.
+func (a AcceptedTypes) SetHeaders(h http.Header) {
+	if len(a.Post) != 0 {
+		h.Set(headerAcceptPost, strings.Join(a.Post, ", "))
+	}
+	if len(a.Patch) != 0 {
+		h.Set(headerAcceptPatch, strings.Join(a.Patch, ", "))
+	}
+}
+
+// Accepts reports whether a request body of the given media type is
+// accepted by method. Media ranges match as in Type, so "text/*" accepts
+// "text/plain" and "application/json;charset=utf-8" only accepts
+// "application/json" with that parameter. Accepts parses the media ranges of
+// a on each call, Handler parses them once.
+func (a AcceptedTypes) Accepts(method, contentType string) bool {
+	return a.compile().accepts(method, contentType)
+}
+
+// Handler returns a handler which advertises a in the response to OPTIONS
+// requests, and responds to POST and PATCH requests with a body whose
+// Content-Type isn't accepted with 415 Unsupported Media Type and the
+// advertisement. Requests without a body aren't checked.
+func (a AcceptedTypes) Handler(h http.Handler) http.Handler {
+	accepted := a.compile()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodOptions:
+			a.SetHeaders(w.Header())
+		case r.ContentLength != 0 && !accepted.accepts(r.Method, r.Header.Get(headerContentType)):
+			a.SetHeaders(w.Header())
+			http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// acceptedSpecs maps the methods whose request bodies are checked to their
+// parsed media ranges.
+type acceptedSpecs map[string]specs
+
+func (a AcceptedTypes) compile() acceptedSpecs {
+	accepted := make(acceptedSpecs, 2)
+
+	if len(a.Post) != 0 {
+		accepted[http.MethodPost] = parseMediaRanges(a.Post)
+	}
+	if len(a.Patch) != 0 {
+		accepted[http.MethodPatch] = parseMediaRanges(a.Patch)
+	}
+
+	return accepted
+}
+
+func (accepted acceptedSpecs) accepts(method, contentType string) bool {
+	ss, checked := accepted[method]
+	if !checked {
+		return true
+	}
+	if strings.TrimSpace(contentType) == "" {
+		return false
+	}
+
+	q, _ := mediaRangeMatch(splitMediaType(contentType), ss)
+
+	return q > 0
+}
+
+// parseMediaRanges parses media ranges such as "text/*" like the elements of
+// an Accept header.
+func parseMediaRanges(ranges []string) specs {
+	p := newHeaderParser(nil, true)
+	ss := make(specs, 0, len(ranges))
+
+	for _, r := range ranges {
+		if s, ok := p.parseElement(r); ok {
+			s.index = len(ss)
+			ss = append(ss, s)
+		}
+	}
+
+	return ss
+}
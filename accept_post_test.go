@@ -0,0 +1,102 @@
+package negotiator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var acceptedTypes = AcceptedTypes{
+	Post:  []string{"application/json", "text/*", "application/xml;charset=utf-8"},
+	Patch: []string{"application/merge-patch+json", "application/json-patch+json"},
+}
+
+func setUpAcceptedTypesHandler() http.Handler {
+	return acceptedTypes.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+}
+
+type AcceptPostSuite struct {
+	suite.Suite
+}
+
+func (s AcceptPostSuite) TestAccepts() {
+	s.True(acceptedTypes.Accepts(http.MethodPost, "application/json"))
+	s.True(acceptedTypes.Accepts(http.MethodPost, "Application/JSON; charset=utf-8"))
+	s.True(acceptedTypes.Accepts(http.MethodPost, "text/csv"))
+	s.True(acceptedTypes.Accepts(http.MethodPost, "application/xml; charset=UTF-8"))
+	s.False(acceptedTypes.Accepts(http.MethodPost, "application/xml"))
+	s.False(acceptedTypes.Accepts(http.MethodPost, "image/png"))
+	s.False(acceptedTypes.Accepts(http.MethodPost, ""))
+
+	s.True(acceptedTypes.Accepts(http.MethodPatch, "application/merge-patch+json"))
+	s.False(acceptedTypes.Accepts(http.MethodPatch, "application/json"))
+
+	s.True(acceptedTypes.Accepts(http.MethodPut, "image/png"))
+	s.True(AcceptedTypes{}.Accepts(http.MethodPost, ""))
+	s.False(AcceptedTypes{Post: []string{";"}}.Accepts(http.MethodPost, "text/plain"))
+}
+
+func (s AcceptPostSuite) TestSetHeaders() {
+	h := make(http.Header)
+	acceptedTypes.SetHeaders(h)
+
+	s.Equal("application/json, text/*, application/xml;charset=utf-8", h.Get(headerAcceptPost))
+	s.Equal("application/merge-patch+json, application/json-patch+json", h.Get(headerAcceptPatch))
+
+	h = make(http.Header)
+	AcceptedTypes{Patch: []string{"application/json-patch+json"}}.SetHeaders(h)
+
+	s.Equal("", h.Get(headerAcceptPost))
+}
+
+func (s AcceptPostSuite) TestHandlerAccepted() {
+	req := httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader("{}"))
+	req.Header.Set(headerContentType, "application/merge-patch+json")
+	w := httptest.NewRecorder()
+	setUpAcceptedTypesHandler().ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("", w.Header().Get(headerAcceptPatch))
+}
+
+func (s AcceptPostSuite) TestHandlerUnsupported() {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("{}"))
+	req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	setUpAcceptedTypesHandler().ServeHTTP(w, req)
+
+	s.Equal(http.StatusUnsupportedMediaType, w.Code)
+	s.Equal("application/json, text/*, application/xml;charset=utf-8", w.Header().Get(headerAcceptPost))
+	s.Equal("application/merge-patch+json, application/json-patch+json", w.Header().Get(headerAcceptPatch))
+}
+
+func (s AcceptPostSuite) TestHandlerWithoutBody() {
+	w := httptest.NewRecorder()
+	setUpAcceptedTypesHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/1/activate", nil))
+
+	s.Equal(http.StatusOK, w.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("{}"))
+	w = httptest.NewRecorder()
+	setUpAcceptedTypesHandler().ServeHTTP(w, req)
+
+	s.Equal(http.StatusUnsupportedMediaType, w.Code)
+}
+
+func (s AcceptPostSuite) TestHandlerOptions() {
+	w := httptest.NewRecorder()
+	setUpAcceptedTypesHandler().ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/users", nil))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("application/json, text/*, application/xml;charset=utf-8", w.Header().Get(headerAcceptPost))
+	s.Equal("application/merge-patch+json, application/json-patch+json", w.Header().Get(headerAcceptPatch))
+}
+
+func TestAcceptPost(t *testing.T) {
+	suite.Run(t, new(AcceptPostSuite))
+}
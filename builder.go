@@ -0,0 +1,174 @@
+package negotiator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MediaParam is a parameter of a media range, such as "format=flowed".
+type MediaParam struct {
+	Name  string
+	Value string
+}
+
+// AcceptEntry is an entry of an Accept, Accept-Language, Accept-Encoding or
+// Accept-Charset header, see BuildAccept.
+type AcceptEntry struct {
+	// Value is a media range, language range, content coding or charset.
+	Value string
+	// Params are the parameters of a media range. Only Accept entries can
+	// have parameters.
+	Params []MediaParam
+	// Q is the quality of the entry, from 0 to 1. Zero means the default
+	// quality 1, which is omitted.
+	Q float64
+	// Exclude marks the entry as not acceptable, with q=0.
+	Exclude bool
+}
+
+// Entry returns an AcceptEntry for value with quality 1.
+func Entry(value string) AcceptEntry {
+	return AcceptEntry{Value: value}
+}
+
+// WithQ returns a copy of e with the quality q. A q of 0 excludes e.
+func (e AcceptEntry) WithQ(q float64) AcceptEntry {
+	e.Q, e.Exclude = q, q == 0
+	return e
+}
+
+// WithParam returns a copy of e with the media range parameter name=value
+// added.
+func (e AcceptEntry) WithParam(name, value string) AcceptEntry {
+	e.Params = append(append([]MediaParam(nil), e.Params...), MediaParam{Name: name, Value: value})
+	return e
+}
+
+// BuildError reports an AcceptEntry which can't be formatted.
+type BuildError struct {
+	// Header is the name of the header.
+	Header string
+	// Index is the index of the invalid entry, counting from 0.
+	Index int
+	// Reason describes what's invalid, e.g. "invalid q".
+	Reason string
+}
+
+func (e *BuildError) Error() string {
+	return "negotiator: invalid " + e.Header + " entry " + strconv.Itoa(e.Index) + ": " + e.Reason
+}
+
+// BuildAccept formats entries as the value of the HTTP Accept header, quoting
+// parameter values as needed and formatting q with at most three decimals.
+// Parameter values can't contain whitespace, commas, semicolons, quotes or
+// backslashes, which the parser of this package doesn't keep in quoted
+// strings.
+func BuildAccept(entries ...AcceptEntry) (string, error) {
+	return buildHeader(headerAccept, entries, validMediaRange)
+}
+
+// BuildAcceptLanguage formats entries as the value of the HTTP
+// Accept-Language header.
+func BuildAcceptLanguage(entries ...AcceptEntry) (string, error) {
+	return buildHeader(headerAcceptLanguage, entries, validLanguageRange)
+}
+
+// BuildAcceptEncoding formats entries as the value of the HTTP
+// Accept-Encoding header.
+func BuildAcceptEncoding(entries ...AcceptEntry) (string, error) {
+	return buildHeader(headerAcceptEncoding, entries, isToken)
+}
+
+// BuildAcceptCharset formats entries as the value of the HTTP
+// Accept-Charset header.
+func BuildAcceptCharset(entries ...AcceptEntry) (string, error) {
+	return buildHeader(headerAcceptCharset, entries, isToken)
+}
+
+func buildHeader(headerName string, entries []AcceptEntry, valid func(string) bool) (string, error) {
+	var b strings.Builder
+
+	for i, e := range entries {
+		fail := func(reason string) (string, error) {
+			return "", &BuildError{Header: headerName, Index: i, Reason: reason}
+		}
+
+		if !valid(e.Value) {
+			return fail("invalid value " + strconv.Quote(e.Value))
+		}
+		if len(e.Params) != 0 && headerName != headerAccept {
+			return fail("unexpected parameters")
+		}
+		if math.IsNaN(e.Q) || e.Q < 0 || e.Q > 1 {
+			return fail("invalid q")
+		}
+
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(e.Value)
+
+		for _, p := range e.Params {
+			if !isToken(p.Name) || strings.EqualFold(p.Name, "q") {
+				return fail("invalid parameter " + strconv.Quote(p.Name))
+			}
+			for j := 0; j < len(p.Value); j++ {
+				if c := p.Value[j]; c <= ' ' || c == 0x7f || strings.IndexByte(`",;\`, c) != -1 {
+					return fail("invalid parameter value " + strconv.Quote(p.Value))
+				}
+			}
+
+			b.WriteString(";" + p.Name + "=" + quoteWord(p.Value))
+		}
+
+		switch q := e.Q; {
+		case e.Exclude:
+			b.WriteString(";q=0")
+		case q == 0 || q == 1:
+			// The default quality is omitted.
+		case q < 0.001:
+			// Don't round an acceptable entry to q=0.
+			b.WriteString(";q=0.001")
+		default:
+			b.WriteString(";q=" + formatQ(q))
+		}
+	}
+
+	return b.String(), nil
+}
+
+// validMediaRange reports whether s is a media range such as "text/html",
+// "text/*" or "*/*".
+func validMediaRange(s string) bool {
+	i := strings.IndexByte(s, '/')
+	if i == -1 {
+		return false
+	}
+
+	typ, subtype := s[:i], s[i+1:]
+
+	return isToken(typ) && isToken(subtype) && (typ != "*" || subtype == "*")
+}
+
+// validLanguageRange reports whether s is a language range such as "en-US"
+// or "*", as defined in RFC 4647.
+func validLanguageRange(s string) bool {
+	if s == "*" {
+		return true
+	}
+
+	for i, subtag := range strings.Split(s, "-") {
+		if len(subtag) == 0 || len(subtag) > 8 {
+			return false
+		}
+
+		for j := 0; j < len(subtag); j++ {
+			if c := subtag[j]; !isAlpha(c) && (i == 0 || !isDigit(c)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
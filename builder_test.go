@@ -0,0 +1,133 @@
+package negotiator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BuilderSuite struct {
+	suite.Suite
+}
+
+func (s BuilderSuite) TestBuildAccept() {
+	val, err := BuildAccept(
+		Entry("text/html"),
+		Entry("application/ld+json").WithParam("profile", "urn:example:a/b").WithQ(0.9),
+		Entry("text/plain").WithParam("format", "flowed").WithQ(0.12345),
+		Entry("*/*").WithQ(0),
+	)
+
+	s.Nil(err)
+	s.Equal(`text/html, application/ld+json;profile="urn:example:a/b";q=0.9, text/plain;format=flowed;q=0.123, */*;q=0`, val)
+}
+
+func (s BuilderSuite) TestBuildOtherHeaders() {
+	val, err := BuildAcceptLanguage(Entry("en-US"), Entry("en").WithQ(0.8), Entry("*").WithQ(0.1))
+	s.Nil(err)
+	s.Equal("en-US, en;q=0.8, *;q=0.1", val)
+
+	val, err = BuildAcceptEncoding(Entry("gzip"), Entry("identity").WithQ(0.0001))
+	s.Nil(err)
+	s.Equal("gzip, identity;q=0.001", val)
+
+	val, err = BuildAcceptCharset(Entry("utf-8"), Entry("iso-8859-1").WithQ(0.5))
+	s.Nil(err)
+	s.Equal("utf-8, iso-8859-1;q=0.5", val)
+
+	val, err = BuildAccept()
+	s.Nil(err)
+	s.Equal("", val)
+}
+
+func (s BuilderSuite) TestQuoting() {
+	val, err := BuildAccept(Entry("text/plain").WithParam("title", "(hi)"))
+
+	s.Nil(err)
+	s.Equal(`text/plain;title="(hi)"`, val)
+}
+
+func (s BuilderSuite) TestZeroValue() {
+	val, err := BuildAccept(AcceptEntry{Value: "text/html"}, AcceptEntry{Value: "text/plain", Q: 0.5}, AcceptEntry{Value: "*/*", Exclude: true})
+
+	s.Nil(err)
+	s.Equal("text/html, text/plain;q=0.5, */*;q=0", val)
+}
+
+func (s BuilderSuite) TestErrors() {
+	for _, test := range []struct {
+		build func() (string, error)
+		err   *BuildError
+	}{
+		{func() (string, error) { return BuildAccept(Entry("text/html"), Entry("text")) }, &BuildError{Header: headerAccept, Index: 1, Reason: `invalid value "text"`}},
+		{func() (string, error) { return BuildAccept(Entry("*/html")) }, &BuildError{Header: headerAccept, Index: 0, Reason: `invalid value "*/html"`}},
+		{func() (string, error) { return BuildAccept(Entry("text/html").WithQ(1.5)) }, &BuildError{Header: headerAccept, Index: 0, Reason: "invalid q"}},
+		{func() (string, error) { return BuildAccept(Entry("text/html").WithQ(math.NaN())) }, &BuildError{Header: headerAccept, Index: 0, Reason: "invalid q"}},
+		{func() (string, error) { return BuildAccept(Entry("text/html").WithParam("q", "1")) }, &BuildError{Header: headerAccept, Index: 0, Reason: `invalid parameter "q"`}},
+		{func() (string, error) { return BuildAccept(Entry("text/html").WithParam("a", "\n")) }, &BuildError{Header: headerAccept, Index: 0, Reason: `invalid parameter value "\n"`}},
+		{func() (string, error) { return BuildAccept(Entry("text/plain").WithParam("title", "a, b;c")) }, &BuildError{Header: headerAccept, Index: 0, Reason: `invalid parameter value "a, b;c"`}},
+		{func() (string, error) { return BuildAccept(Entry("text/plain").WithParam("title", "A b")) }, &BuildError{Header: headerAccept, Index: 0, Reason: `invalid parameter value "A b"`}},
+		{func() (string, error) { return BuildAccept(Entry("text/plain").WithParam("title", `say"hi"`)) }, &BuildError{Header: headerAccept, Index: 0, Reason: `invalid parameter value "say\"hi\""`}},
+		{func() (string, error) { return BuildAccept(Entry("text/plain").WithParam("title", `a\b`)) }, &BuildError{Header: headerAccept, Index: 0, Reason: `invalid parameter value "a\\b"`}},
+		{func() (string, error) { return BuildAcceptLanguage(Entry("en_US")) }, &BuildError{Header: headerAcceptLanguage, Index: 0, Reason: `invalid value "en_US"`}},
+		{func() (string, error) { return BuildAcceptLanguage(Entry("en").WithParam("a", "b")) }, &BuildError{Header: headerAcceptLanguage, Index: 0, Reason: "unexpected parameters"}},
+		{func() (string, error) { return BuildAcceptEncoding(Entry("gzip, br")) }, &BuildError{Header: headerAcceptEncoding, Index: 0, Reason: `invalid value "gzip, br"`}},
+	} {
+		_, err := test.build()
+		s.Equal(test.err, err)
+	}
+
+	_, err := BuildAcceptCharset(Entry(""))
+	s.EqualError(err, `negotiator: invalid Accept-Charset entry 0: invalid value ""`)
+}
+
+func (s BuilderSuite) TestWithParamCopies() {
+	e := Entry("text/plain").WithParam("a", "1")
+	e.WithParam("b", "2")
+
+	s.Equal([]MediaParam{{Name: "a", Value: "1"}}, e.Params)
+}
+
+func (s BuilderSuite) TestRoundTrip() {
+	for _, test := range []struct {
+		headerName string
+		build      func(entries ...AcceptEntry) (string, error)
+		entries    []AcceptEntry
+		// offers are the offers matching each entry.
+		offers []string
+	}{
+		{headerAccept, BuildAccept, []AcceptEntry{
+			Entry("text/html").WithQ(0.5),
+			Entry("application/ld+json").WithParam("profile", "urn:example:a/b").WithQ(0.9),
+			Entry("text/plain").WithParam("title", "(Hi)").WithParam("format", "flowed").WithQ(0.1),
+			Entry("image/*").WithQ(0.25),
+			Entry("*/*").WithQ(0),
+		}, []string{"text/html", "application/ld+json;profile=urn:example:a/b", `text/plain;format=flowed;title="(Hi)"`, "image/png", "video/mp4"}},
+		{headerAcceptLanguage, BuildAcceptLanguage, []AcceptEntry{Entry("de-CH"), Entry("de").WithQ(0.333)}, []string{"de-CH", "de"}},
+		{headerAcceptEncoding, BuildAcceptEncoding, []AcceptEntry{Entry("br"), Entry("gzip").WithQ(0.999)}, []string{"br", "gzip"}},
+		{headerAcceptCharset, BuildAcceptCharset, []AcceptEntry{Entry("utf-8").WithQ(0.001)}, []string{"utf-8"}},
+	} {
+		val, err := test.build(test.entries...)
+		s.Nil(err)
+
+		n := setUpNegotiator(test.headerName, val)
+		s.Nil(New(n.header, WithStrict()).Validate(test.headerName), val)
+
+		for i, e := range test.entries {
+			q := 1.0
+			if e.Q != 0 || e.Exclude {
+				q = e.Q
+			}
+
+			s.Equal(q, n.quality(test.headerName, test.offers[i]), test.offers[i])
+			if q > 0 {
+				s.Equal(test.offers[i], n.selectOffer(test.headerName, test.offers[i:i+1]), test.offers[i])
+			}
+		}
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	suite.Run(t, new(BuilderSuite))
+}
@@ -0,0 +1,153 @@
+package negotiator
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// Cache is a bounded, least recently used cache of parsed header values. A
+// Cache is safe for concurrent use and is meant to be shared by all the
+// Negotiators of a process, see WithCache. Entries are keyed on the options
+// which affect parsing, so Negotiators with different options can share it.
+type Cache struct {
+	mu        sync.Mutex
+	size      int
+	decisions bool
+	ll        *list.List
+	items     map[cacheKey]*list.Element
+	hits      uint64
+	misses    uint64
+}
+
+type cacheKey struct {
+	header string
+	value  string
+	// offers is empty for parsed specs, otherwise the key of an OfferSet.
+	offers   string
+	strict   bool
+	limits   Limits
+	tieBreak TieBreak
+}
+
+func newCacheKey(p headerParser, headerName, headerVal, offers string) cacheKey {
+	return cacheKey{
+		header:   headerName,
+		value:    headerVal,
+		offers:   offers,
+		strict:   p.strict,
+		limits:   p.limits,
+		tieBreak: p.tieBreak,
+	}
+}
+
+type cacheEntry struct {
+	key      cacheKey
+	specs    specs
+	err      error
+	decision string
+}
+
+// CacheStats represents the statistics of a Cache.
+type CacheStats struct {
+	// Hits is the number of lookups which found an entry.
+	Hits uint64
+	// Misses is the number of lookups which didn't find an entry.
+	Misses uint64
+	// Len is the number of entries.
+	Len int
+	// Size is the maximum number of entries.
+	Size int
+}
+
+// NewCache creates a Cache which holds at most size entries. If decisions is
+// true, the cache also stores the result of negotiating a header value against
+// an OfferSet, so that repeated negotiations skip matching as well. Variadic
+// offers are never cached, as building their key costs about as much as
+// matching them.
+func NewCache(size int, decisions bool) *Cache {
+	return &Cache{
+		size:      size,
+		decisions: decisions,
+		ll:        list.New(),
+		items:     make(map[cacheKey]*list.Element),
+	}
+}
+
+// Stats returns the statistics of c.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{Hits: c.hits, Misses: c.misses, Len: c.ll.Len(), Size: c.size}
+}
+
+func (c *Cache) get(key cacheKey) (entry *cacheEntry, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return
+	}
+
+	c.hits++
+	c.ll.MoveToFront(el)
+
+	return el.Value.(*cacheEntry), true
+}
+
+func (c *Cache) add(entry *cacheEntry) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[entry.key]; ok {
+		el.Value = entry
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[entry.key] = c.ll.PushFront(entry)
+
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *Cache) specs(p headerParser, headerName, headerVal string, parse func() (specs, error)) (specs, error) {
+	key := newCacheKey(p, headerName, headerVal, "")
+
+	if entry, ok := c.get(key); ok {
+		return entry.specs, entry.err
+	}
+
+	ss, err := parse()
+	c.add(&cacheEntry{key: key, specs: ss, err: err})
+
+	return ss, err
+}
+
+func (c *Cache) decision(p headerParser, headerName, headerVal, offers string, selectOffer func() string) string {
+	key := newCacheKey(p, headerName, headerVal, offers)
+
+	if entry, ok := c.get(key); ok {
+		return entry.decision
+	}
+
+	decision := selectOffer()
+	c.add(&cacheEntry{key: key, decision: decision})
+
+	return decision
+}
+
+// offersKey returns the key of the OfferSet of offers in a Cache.
+func offersKey(offers []string) string {
+	return "\x00" + strings.Join(offers, "\x00")
+}
@@ -0,0 +1,129 @@
+package negotiator
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheSuite struct {
+	suite.Suite
+}
+
+func (s CacheSuite) TestSpecs() {
+	cache := NewCache(8, false)
+
+	header := make(http.Header)
+	header.Set(headerAccept, "text/html, application/*;q=0.9")
+
+	s.Equal("application/json", New(header, WithCache(cache)).Type("application/json"))
+	s.Equal("text/html", New(header, WithCache(cache)).Type("application/json", "text/html"))
+	s.Equal(CacheStats{Hits: 1, Misses: 1, Len: 1, Size: 8}, cache.Stats())
+}
+
+func (s CacheSuite) TestDecisions() {
+	cache := NewCache(8, true)
+	both := NewOfferSet("application/json", "text/html")
+	json := NewOfferSet("application/json")
+
+	header := make(http.Header)
+	header.Set(headerAccept, "text/html, application/*;q=0.9")
+
+	s.Equal("text/html", New(header, WithCache(cache)).SelectType(both))
+	s.Equal("text/html", New(header, WithCache(cache)).SelectType(both))
+	s.Equal("application/json", New(header, WithCache(cache)).SelectType(json))
+
+	// The third negotiation misses its decision but hits the parsed specs.
+	s.Equal(CacheStats{Hits: 2, Misses: 3, Len: 3, Size: 8}, cache.Stats())
+}
+
+func (s CacheSuite) TestVariadicNotDecided() {
+	cache := NewCache(8, true)
+
+	header := make(http.Header)
+	header.Set(headerAccept, "text/html, application/*;q=0.9")
+
+	s.Equal("text/html", New(header, WithCache(cache)).Type("application/json", "text/html"))
+	s.Equal("text/html", New(header, WithCache(cache)).Type("application/json", "text/html"))
+
+	// Only the parsed specs are cached.
+	s.Equal(CacheStats{Hits: 1, Misses: 1, Len: 1, Size: 8}, cache.Stats())
+}
+
+func (s CacheSuite) TestHeadersAreSeparate() {
+	cache := NewCache(8, false)
+
+	header := make(http.Header)
+	header.Set(headerAcceptLanguage, "en")
+	header.Set(headerAcceptCharset, "en")
+
+	n := New(header, WithCache(cache))
+	s.Equal("en", n.Language("en"))
+	s.Equal("en", n.Charset("en"))
+	s.Equal(uint64(2), cache.Stats().Misses)
+}
+
+func (s CacheSuite) TestOptionsAreSeparate() {
+	cache := NewCache(8, true)
+	languages := NewOfferSet("de", "en")
+
+	header := make(http.Header)
+	header.Set(headerAcceptLanguage, "en, de, fr;q=abc")
+
+	s.Nil(New(header, WithCache(cache)).Validate(headerAcceptLanguage))
+	s.NotNil(New(header, WithCache(cache), WithStrict()).Validate(headerAcceptLanguage))
+	s.Equal(1, len(New(header, WithCache(cache), WithLimits(Limits{MaxElements: 1})).specs(headerAcceptLanguage)))
+
+	s.Equal("de", New(header, WithCache(cache)).SelectLanguage(languages))
+	s.Equal("en", New(header, WithCache(cache), WithTieBreak(TieBreakClient)).SelectLanguage(languages))
+}
+
+func (s CacheSuite) TestEviction() {
+	cache := NewCache(2, false)
+
+	for _, accept := range []string{"text/html", "text/plain", "text/html", "application/json", "text/plain"} {
+		header := make(http.Header)
+		header.Set(headerAccept, accept)
+		New(header, WithCache(cache)).Type()
+	}
+
+	s.Equal(CacheStats{Hits: 1, Misses: 4, Len: 2, Size: 2}, cache.Stats())
+}
+
+func (s CacheSuite) TestZeroSize() {
+	cache := NewCache(0, false)
+
+	header := make(http.Header)
+	header.Set(headerAccept, "text/html")
+	s.Equal("text/html", New(header, WithCache(cache)).Type())
+	s.Equal(0, cache.Stats().Len)
+}
+
+func TestCache(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
+
+func BenchmarkNegotiatorCached(b *testing.B) {
+	header := setUpBrowserHeader()
+	cache := NewCache(64, false)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		negotiateAll(New(header, WithCache(cache)))
+	}
+}
+
+func BenchmarkNegotiatorCachedDecisions(b *testing.B) {
+	header := setUpBrowserHeader()
+	cache := NewCache(64, true)
+	types := NewOfferSet("application/json", "text/html")
+	languages := NewOfferSet("de", "en")
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		n := New(header, WithCache(cache))
+		n.SelectType(types)
+		n.SelectLanguage(languages)
+	}
+}
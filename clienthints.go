@@ -0,0 +1,226 @@
+package negotiator
+
+import (
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/go-http-utils/negotiator/sf"
+)
+
+const (
+	headerAcceptCH                = "Accept-CH"
+	headerCriticalCH              = "Critical-CH"
+	headerSecCHDPR                = "Sec-CH-DPR"
+	headerSecCHViewportWidth      = "Sec-CH-Viewport-Width"
+	headerSecCHPrefersColorScheme = "Sec-CH-Prefers-Color-Scheme"
+	headerSaveData                = "Save-Data"
+)
+
+// ClientHints are the client hints a server asks user agents to send.
+type ClientHints struct {
+	// Accept are the hints sent in the HTTP Accept-CH header.
+	Accept []string
+	// Critical are the hints sent in the HTTP Critical-CH header. A user
+	// agent which didn't send them retries the request with them. Critical
+	// hints are also added to Accept-CH.
+	Critical []string
+}
+
+// SetHeaders sets the HTTP Accept-CH and Critical-CH headers of c to h.
+func (c ClientHints) SetHeaders(h http.Header) {
+	accept := append([]string(nil), c.Accept...)
+
+	for _, hint := range c.Critical {
+		if !containsFold(accept, hint) {
+			accept = append(accept, hint)
+		}
+	}
+
+	if len(accept) != 0 {
+		h.Set(headerAcceptCH, strings.Join(accept, ", "))
+	}
+	if len(c.Critical) != 0 {
+		h.Set(headerCriticalCH, strings.Join(c.Critical, ", "))
+	}
+}
+
+// ClientHintsHandler returns a handler which attaches a Negotiator to the
+// request context, like Handler. When the response header is written, it
+// sets the HTTP Accept-CH and Critical-CH headers of hints, and adds the
+// hints consulted through FromRequest(r) to Vary.
+func ClientHintsHandler(h http.Handler, hints ClientHints, opts ...Option) http.Handler {
+	return Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := FromRequest(r)
+
+		serveWithHeaders(h, w, r, func(h http.Header) {
+			hints.SetHeaders(h)
+			for _, hint := range n.ConsultedHints() {
+				h.Add(headerVary, hint)
+			}
+		})
+	}), opts...)
+}
+
+// DPR returns the device pixel ratio of the HTTP Sec-CH-DPR header.
+func (n *Negotiator) DPR() (dpr float64, ok bool) {
+	switch val := n.hintItem(headerSecCHDPR).(type) {
+	case float64:
+		dpr = val
+	case int64:
+		dpr = float64(val)
+	}
+
+	return dpr, dpr > 0
+}
+
+// ViewportWidth returns the layout viewport width in CSS pixels of the HTTP
+// Sec-CH-Viewport-Width header.
+func (n *Negotiator) ViewportWidth() (width int, ok bool) {
+	val, _ := n.hintItem(headerSecCHViewportWidth).(int64)
+	if val <= 0 || val > math.MaxInt32 {
+		return 0, false
+	}
+
+	return int(val), true
+}
+
+// PrefersColorScheme returns the color scheme of the HTTP
+// Sec-CH-Prefers-Color-Scheme header, such as "light" or "dark".
+func (n *Negotiator) PrefersColorScheme() (scheme string, ok bool) {
+	switch val := n.hintItem(headerSecCHPrefersColorScheme).(type) {
+	case string:
+		scheme = val
+	case sf.Token:
+		scheme = string(val)
+	}
+
+	return scheme, scheme != ""
+}
+
+// SaveData reports whether the HTTP Save-Data header asks for reduced data
+// usage.
+func (n *Negotiator) SaveData() bool {
+	return strings.EqualFold(n.hint(headerSaveData), "on")
+}
+
+// ConsultedHints returns the names of the client hint headers consulted so
+// far, which a response must list in Vary.
+func (n *Negotiator) ConsultedHints() []string {
+	n.decisionsMu.Lock()
+	defer n.decisionsMu.Unlock()
+
+	return append([]string(nil), n.hints...)
+}
+
+// hint returns the value of the given client hint header and records it as
+// consulted.
+func (n *Negotiator) hint(headerName string) string {
+	n.decisionsMu.Lock()
+	if !containsFold(n.hints, headerName) {
+		n.hints = append(n.hints, headerName)
+	}
+	n.decisionsMu.Unlock()
+
+	return strings.TrimSpace(n.header.Get(headerName))
+}
+
+// hintItem returns the bare item of the given structured client hint header,
+// or nil if it is absent or malformed.
+func (n *Negotiator) hintItem(headerName string) interface{} {
+	n.hint(headerName)
+
+	item, err := sf.ParseItem(structuredField(n.header, headerName))
+	if err != nil {
+		return nil
+	}
+
+	return item.Value
+}
+
+// structuredField returns the value of a structured field header, combining
+// multiple field lines.
+func structuredField(header http.Header, headerName string) string {
+	return strings.Join(header[http.CanonicalHeaderKey(headerName)], ", ")
+}
+
+// ImageVariant represents a variant of an image.
+type ImageVariant struct {
+	// URI is the URI of the variant.
+	URI string
+	// Width is the width of the variant in pixels, or 0 if unknown.
+	Width int
+	// DPR is the device pixel ratio the variant is meant for, or 0 if
+	// unknown.
+	DPR float64
+}
+
+// SelectImage selects the variant best suited to the client hints of the
+// request. If the viewport width is known, it returns the narrowest variant
+// at least as wide as the viewport in device pixels, otherwise the variant
+// with the lowest DPR at least as high as the device pixel ratio. If no
+// variant is large enough, the largest one is returned. Save-Data makes the
+// device pixel ratio count as 1. ok is false if there are no variants.
+func (n *Negotiator) SelectImage(variants []ImageVariant) (variant ImageVariant, ok bool) {
+	if len(variants) == 0 {
+		return
+	}
+
+	dpr, hasDPR := n.DPR()
+	if !hasDPR || n.SaveData() {
+		dpr = 1
+	}
+
+	if width, ok := n.ViewportWidth(); ok && hasWidths(variants) {
+		target := float64(width) * dpr
+
+		return selectImage(variants, target, func(v ImageVariant) float64 {
+			return float64(v.Width)
+		}), true
+	}
+
+	return selectImage(variants, dpr, func(v ImageVariant) float64 {
+		return v.DPR
+	}), true
+}
+
+// selectImage returns the variant with the smallest size at least target,
+// or the largest variant.
+func selectImage(variants []ImageVariant, target float64, size func(ImageVariant) float64) ImageVariant {
+	best, largest := -1, 0
+
+	for i, v := range variants {
+		if size(v) > size(variants[largest]) {
+			largest = i
+		}
+		if size(v) >= target && (best == -1 || size(v) < size(variants[best])) {
+			best = i
+		}
+	}
+
+	if best == -1 {
+		return variants[largest]
+	}
+
+	return variants[best]
+}
+
+func hasWidths(variants []ImageVariant) bool {
+	for _, v := range variants {
+		if v.Width > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(vals []string, val string) bool {
+	for _, v := range vals {
+		if strings.EqualFold(v, val) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,162 @@
+package negotiator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var imageVariants = []ImageVariant{
+	{URI: "/img/800.jpg", Width: 800, DPR: 1},
+	{URI: "/img/1600.jpg", Width: 1600, DPR: 2},
+	{URI: "/img/400.jpg", Width: 400, DPR: 0.5},
+}
+
+func setUpHintsNegotiator(hints map[string]string) *Negotiator {
+	header := make(http.Header)
+	for name, val := range hints {
+		header.Set(name, val)
+	}
+
+	return New(header)
+}
+
+type ClientHintsSuite struct {
+	suite.Suite
+}
+
+func (s ClientHintsSuite) TestSetHeaders() {
+	h := make(http.Header)
+	ClientHints{Accept: []string{headerSecCHDPR}, Critical: []string{headerSecCHViewportWidth, "sec-ch-dpr"}}.SetHeaders(h)
+
+	s.Equal("Sec-CH-DPR, Sec-CH-Viewport-Width", h.Get(headerAcceptCH))
+	s.Equal("Sec-CH-Viewport-Width, sec-ch-dpr", h.Get(headerCriticalCH))
+
+	h = make(http.Header)
+	ClientHints{}.SetHeaders(h)
+
+	s.Empty(h)
+}
+
+func (s ClientHintsSuite) TestHints() {
+	n := setUpHintsNegotiator(map[string]string{
+		headerSecCHDPR:                "2.5",
+		headerSecCHViewportWidth:      "412",
+		headerSecCHPrefersColorScheme: `"dark"`,
+		headerSaveData:                "on",
+	})
+
+	dpr, ok := n.DPR()
+	s.True(ok)
+	s.Equal(2.5, dpr)
+
+	width, ok := n.ViewportWidth()
+	s.True(ok)
+	s.Equal(412, width)
+
+	scheme, ok := n.PrefersColorScheme()
+	s.True(ok)
+	s.Equal("dark", scheme)
+
+	s.True(n.SaveData())
+	s.Equal([]string{headerSecCHDPR, headerSecCHViewportWidth, headerSecCHPrefersColorScheme, headerSaveData}, n.ConsultedHints())
+}
+
+func (s ClientHintsSuite) TestInvalidHints() {
+	n := setUpHintsNegotiator(map[string]string{
+		headerSecCHDPR:           "-1",
+		headerSecCHViewportWidth: "wide",
+		headerSaveData:           "off",
+	})
+
+	_, ok := n.DPR()
+	s.False(ok)
+
+	_, ok = n.ViewportWidth()
+	s.False(ok)
+
+	_, ok = n.PrefersColorScheme()
+	s.False(ok)
+
+	s.False(n.SaveData())
+}
+
+func (s ClientHintsSuite) TestStructuredHints() {
+	n := setUpHintsNegotiator(map[string]string{
+		headerSecCHDPR:                "1.2345",
+		headerSecCHViewportWidth:      "412.0",
+		headerSecCHPrefersColorScheme: "dark",
+	})
+
+	_, ok := n.DPR()
+	s.False(ok)
+
+	_, ok = n.ViewportWidth()
+	s.False(ok)
+
+	scheme, _ := n.PrefersColorScheme()
+	s.Equal("dark", scheme)
+}
+
+func (s ClientHintsSuite) TestSelectImageByWidth() {
+	n := setUpHintsNegotiator(map[string]string{headerSecCHViewportWidth: "412", headerSecCHDPR: "2"})
+
+	v, ok := n.SelectImage(imageVariants)
+	s.True(ok)
+	s.Equal("/img/1600.jpg", v.URI)
+
+	n = setUpHintsNegotiator(map[string]string{headerSecCHViewportWidth: "412"})
+
+	v, _ = n.SelectImage(imageVariants)
+	s.Equal("/img/800.jpg", v.URI)
+
+	n = setUpHintsNegotiator(map[string]string{headerSecCHViewportWidth: "1200", headerSecCHDPR: "3"})
+
+	v, _ = n.SelectImage(imageVariants)
+	s.Equal("/img/1600.jpg", v.URI)
+}
+
+func (s ClientHintsSuite) TestSelectImageByDPR() {
+	n := setUpHintsNegotiator(map[string]string{headerSecCHDPR: "1.5"})
+
+	v, _ := n.SelectImage(imageVariants)
+	s.Equal("/img/1600.jpg", v.URI)
+
+	v, _ = New(nil).SelectImage(imageVariants)
+	s.Equal("/img/800.jpg", v.URI)
+}
+
+func (s ClientHintsSuite) TestSelectImageSaveData() {
+	n := setUpHintsNegotiator(map[string]string{headerSecCHViewportWidth: "412", headerSecCHDPR: "2", headerSaveData: "on"})
+
+	v, _ := n.SelectImage(imageVariants)
+	s.Equal("/img/800.jpg", v.URI)
+}
+
+func (s ClientHintsSuite) TestSelectImageEmpty() {
+	_, ok := New(nil).SelectImage(nil)
+	s.False(ok)
+}
+
+func (s ClientHintsSuite) TestHandler() {
+	hints := ClientHints{Accept: []string{headerSecCHDPR, headerSecCHViewportWidth}}
+	h := ClientHintsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, _ := FromRequest(r).SelectImage(imageVariants)
+		w.Write([]byte(v.URI))
+	}), hints)
+
+	req := httptest.NewRequest(http.MethodGet, "/img", nil)
+	req.Header.Set(headerSecCHDPR, "2")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	s.Equal("/img/1600.jpg", w.Body.String())
+	s.Equal("Sec-CH-DPR, Sec-CH-Viewport-Width", w.Header().Get(headerAcceptCH))
+	s.Equal([]string{headerSecCHDPR, headerSaveData, headerSecCHViewportWidth}, w.Header()[headerVary])
+}
+
+func TestClientHints(t *testing.T) {
+	suite.Run(t, new(ClientHintsSuite))
+}
@@ -0,0 +1,166 @@
+package negotiator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	headerContentEncoding = "Content-Encoding"
+	headerContentLength   = "Content-Length"
+)
+
+// Decoder returns a reader of the decoded content of r.
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+var decoders = struct {
+	sync.RWMutex
+	names []string
+	funcs map[string]Decoder
+}{
+	names: []string{"gzip", "deflate", "identity"},
+	funcs: map[string]Decoder{
+		"gzip": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		"deflate": zlib.NewReader,
+		"identity": func(r io.Reader) (io.ReadCloser, error) {
+			return ioutil.NopCloser(r), nil
+		},
+	},
+}
+
+// RegisterDecoder registers a Decoder for request bodies with the given
+// content coding, such as "br", under its lowercase name. gzip, deflate and
+// identity are registered by default.
+func RegisterDecoder(coding string, fn Decoder) {
+	coding = strings.ToLower(coding)
+
+	decoders.Lock()
+	defer decoders.Unlock()
+
+	if _, ok := decoders.funcs[coding]; !ok {
+		decoders.names = append(decoders.names, coding)
+	}
+	decoders.funcs[coding] = fn
+}
+
+// DecoderCodings returns the content codings of the registered decoders.
+func DecoderCodings() []string {
+	decoders.RLock()
+	defer decoders.RUnlock()
+
+	return append([]string(nil), decoders.names...)
+}
+
+func decoderFunc(coding string) Decoder {
+	decoders.RLock()
+	defer decoders.RUnlock()
+
+	if coding == "x-gzip" {
+		coding = "gzip"
+	}
+
+	return decoders.funcs[coding]
+}
+
+// DefaultMaxDecodedBytes is the maximum size of a decoded request body used
+// by DecodeHandler if maxBytes isn't positive.
+const DefaultMaxDecodedBytes = 10 << 20
+
+// DecodeHandler returns a handler which decodes the body of requests with
+// the HTTP Content-Encoding header using the registered decoders, so that h
+// reads the decoded body. As RFC 7694 defines, a request with an unsupported
+// content coding gets 415 Unsupported Media Type with an Accept-Encoding
+// header listing the supported ones. A body which can't be decoded gets
+// 400 Bad Request, and a body which decodes to more than maxBytes gets
+// 413 Request Entity Too Large, which guards against decompression bombs.
+// The body is decoded before h is called, so maxBytes also bounds the memory
+// used per request.
+func DecodeHandler(h http.Handler, maxBytes int64) http.Handler {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxDecodedBytes
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		codings := contentCodings(r.Header)
+		if len(codings) == 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		fns := make([]Decoder, len(codings))
+		for i, coding := range codings {
+			if fns[i] = decoderFunc(coding); fns[i] == nil {
+				w.Header().Set(headerAcceptEncoding, strings.Join(DecoderCodings(), ", "))
+				http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+
+		body, code := decodeBody(r.Body, fns, maxBytes)
+		if code != http.StatusOK {
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.Header.Del(headerContentEncoding)
+		r2.Header.Set(headerContentLength, strconv.Itoa(len(body)))
+		r2.ContentLength = int64(len(body))
+		r2.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		h.ServeHTTP(w, r2)
+	})
+}
+
+// decodeBody decodes body with fns, which are listed in the order they were
+// applied, reading at most maxBytes of decoded content. The status code is
+// 400 if body can't be decoded and 413 if the decoded content is larger than
+// maxBytes.
+func decodeBody(body io.ReadCloser, fns []Decoder, maxBytes int64) ([]byte, int) {
+	defer body.Close()
+
+	var r io.Reader = body
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		decoded, err := fns[i](r)
+		if err != nil {
+			return nil, http.StatusBadRequest
+		}
+		defer decoded.Close()
+
+		r = decoded
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(r, maxBytes+1))
+	switch {
+	case err != nil:
+		return nil, http.StatusBadRequest
+	case int64(len(b)) > maxBytes:
+		return nil, http.StatusRequestEntityTooLarge
+	}
+
+	return b, http.StatusOK
+}
+
+// contentCodings returns the lowercase content codings of the HTTP
+// Content-Encoding headers of header, in the order they were applied.
+func contentCodings(header http.Header) (codings []string) {
+	for _, val := range header[headerContentEncoding] {
+		for _, coding := range strings.Split(val, ",") {
+			if coding = formatToken(coding); coding != "" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+
+	return
+}
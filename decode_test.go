@@ -0,0 +1,144 @@
+package negotiator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func gzipped(s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(s))
+	w.Close()
+
+	return buf.Bytes()
+}
+
+func deflated(b []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(b)
+	w.Close()
+
+	return buf.Bytes()
+}
+
+func setUpDecodeHandler() http.Handler {
+	return DecodeHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("X-Content-Encoding", r.Header.Get(headerContentEncoding))
+		w.Header().Set("X-Content-Length", r.Header.Get(headerContentLength))
+		w.Write(body)
+	}), 16)
+}
+
+func serveDecode(body []byte, contentEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body))
+	if contentEncoding != "" {
+		req.Header.Set(headerContentEncoding, contentEncoding)
+	}
+
+	w := httptest.NewRecorder()
+	setUpDecodeHandler().ServeHTTP(w, req)
+
+	return w
+}
+
+type DecodeSuite struct {
+	suite.Suite
+}
+
+func (s DecodeSuite) TestNotEncoded() {
+	w := serveDecode([]byte("hello"), "")
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("hello", w.Body.String())
+}
+
+func (s DecodeSuite) TestGzip() {
+	w := serveDecode(gzipped("hello"), "GZIP")
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("hello", w.Body.String())
+	s.Equal("", w.Header().Get("X-Content-Encoding"))
+}
+
+func (s DecodeSuite) TestMultipleCodings() {
+	w := serveDecode(deflated(gzipped("hello")), "gzip, identity, deflate")
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("hello", w.Body.String())
+}
+
+func (s DecodeSuite) TestUnsupported() {
+	w := serveDecode([]byte("hello"), "br")
+
+	s.Equal(http.StatusUnsupportedMediaType, w.Code)
+	s.Equal("gzip, deflate, identity", w.Header().Get(headerAcceptEncoding))
+}
+
+func (s DecodeSuite) TestMalformed() {
+	w := serveDecode([]byte("hello"), "gzip")
+
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s DecodeSuite) TestTooLarge() {
+	w := serveDecode(gzipped(strings.Repeat("a", 17)), "gzip")
+	s.Equal(http.StatusRequestEntityTooLarge, w.Code)
+
+	w = serveDecode(gzipped(strings.Repeat("a", 16)), "gzip")
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("16", w.Header().Get("X-Content-Length"))
+
+	// Unencoded bodies are left to h.
+	w = serveDecode([]byte(strings.Repeat("a", 17)), "")
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s DecodeSuite) TestRequestNotModified() {
+	req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(gzipped("hello")))
+	req.Header.Set(headerContentEncoding, "gzip")
+
+	setUpDecodeHandler().ServeHTTP(httptest.NewRecorder(), req)
+
+	s.Equal("gzip", req.Header.Get(headerContentEncoding))
+}
+
+func (s DecodeSuite) TestRegisterDecoder() {
+	RegisterDecoder("Upper", func(r io.Reader) (io.ReadCloser, error) {
+		b, err := ioutil.ReadAll(r)
+		return ioutil.NopCloser(strings.NewReader(strings.ToUpper(string(b)))), err
+	})
+	defer func() {
+		decoders.Lock()
+		decoders.names = decoders.names[:3]
+		delete(decoders.funcs, "upper")
+		decoders.Unlock()
+	}()
+
+	s.Equal([]string{"gzip", "deflate", "identity", "upper"}, DecoderCodings())
+
+	w := serveDecode(gzipped("hello"), "upper, x-gzip")
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("HELLO", w.Body.String())
+}
+
+func TestDecode(t *testing.T) {
+	suite.Run(t, new(DecodeSuite))
+}
@@ -0,0 +1,191 @@
+package negotiator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-http-utils/negotiator/sf"
+)
+
+const (
+	headerWantContentDigest = "Want-Content-Digest"
+	headerWantReprDigest    = "Want-Repr-Digest"
+	headerContentDigest     = "Content-Digest"
+	headerReprDigest        = "Repr-Digest"
+)
+
+var digests = struct {
+	sync.RWMutex
+	names []string
+	funcs map[string]func() hash.Hash
+}{
+	names: []string{"sha-256", "sha-512"},
+	funcs: map[string]func() hash.Hash{
+		"sha-256": sha256.New,
+		"sha-512": sha512.New,
+	},
+}
+
+// RegisterDigest registers a digest algorithm of the HTTP Digest Algorithm
+// Values registry, such as "sha-256", under its lowercase name. Among equally
+// wanted algorithms, those registered first are preferred. sha-256 and
+// sha-512 are registered by default.
+func RegisterDigest(name string, fn func() hash.Hash) {
+	name = strings.ToLower(name)
+
+	digests.Lock()
+	defer digests.Unlock()
+
+	if _, ok := digests.funcs[name]; !ok {
+		digests.names = append(digests.names, name)
+	}
+	digests.funcs[name] = fn
+}
+
+// DigestAlgorithms returns the names of the registered digest algorithms, in
+// order of preference.
+func DigestAlgorithms() []string {
+	digests.RLock()
+	defer digests.RUnlock()
+
+	return append([]string(nil), digests.names...)
+}
+
+func digestFunc(name string) func() hash.Hash {
+	digests.RLock()
+	defer digests.RUnlock()
+
+	return digests.funcs[name]
+}
+
+// ContentDigest returns the most wanted registered digest algorithm of the
+// HTTP Want-Content-Digest header, as defined in RFC 9530. ok is false if the
+// header is absent or wants none of the registered algorithms.
+func (n *Negotiator) ContentDigest() (algorithm string, ok bool) {
+	algorithm = n.selectOffer(headerWantContentDigest, DigestAlgorithms())
+	return algorithm, algorithm != ""
+}
+
+// ReprDigest is like ContentDigest, but for the HTTP Want-Repr-Digest header.
+func (n *Negotiator) ReprDigest() (algorithm string, ok bool) {
+	algorithm = n.selectOffer(headerWantReprDigest, DigestAlgorithms())
+	return algorithm, algorithm != ""
+}
+
+func isWantDigest(headerName string) bool {
+	return headerName == headerWantContentDigest || headerName == headerWantReprDigest
+}
+
+// parseWantDigest parses a Want-Content-Digest or Want-Repr-Digest header, a
+// dictionary of algorithms and integer preferences from 0 to 10, into specs
+// whose q is a tenth of the preference.
+func (p headerParser) parseWantDigest(headerName string) (specs, error) {
+	headerVal := structuredField(p.header, headerName)
+
+	if max := p.limits.MaxBytes; max > 0 && len(headerVal) > max {
+		switch p.limits.Action {
+		case LimitReject:
+			return p.exceeded(headerName, "bytes", max)
+		case LimitIgnore:
+			// Like an absent header, which wants no digest.
+			headerVal = ""
+		default:
+			members := splitQuoted(headerVal[:max], ',')
+			headerVal = strings.Join(members[:len(members)-1], ",")
+		}
+	}
+
+	d, err := sf.ParseDictionary(headerVal)
+	if err != nil {
+		if sfErr, ok := err.(*sf.Error); ok && p.strict {
+			index := len(splitQuoted(headerVal[:sfErr.Offset], ',')) - 1
+			return nil, &ParseError{Header: headerName, Index: index, Offset: sfErr.Offset, Reason: sfErr.Reason}
+		}
+		return nil, nil
+	}
+
+	var ss specs
+
+	for _, dm := range d {
+		item, ok := dm.Value.(sf.Item)
+		if !ok {
+			continue
+		}
+
+		if weight, ok := item.Value.(int64); ok && weight > 0 && weight <= 10 {
+			ss = append(ss, spec{val: dm.Key, q: qvalue(weight) * maxQ / 10, index: len(ss)})
+		}
+	}
+
+	ss.sort()
+
+	return ss, nil
+}
+
+// DigestHandler returns a handler which attaches a Negotiator to the request
+// context, like Handler, and buffers the response of h. If the request has a
+// HTTP Want-Content-Digest or Want-Repr-Digest header, the response gets a
+// Content-Digest or Repr-Digest header with the digest of its body, computed
+// with the most wanted registered algorithm. As the handler doesn't encode
+// the body, both digests are computed over the same bytes.
+func DigestHandler(h http.Handler, opts ...Option) http.Handler {
+	return Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := FromRequest(r)
+		content, wantContent := n.ContentDigest()
+		repr, wantRepr := n.ReprDigest()
+
+		if !wantContent && !wantRepr {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		dw := &digestWriter{ResponseWriter: w, code: http.StatusOK}
+		h.ServeHTTP(dw, r)
+
+		if dw.code != http.StatusNoContent && dw.code != http.StatusNotModified {
+			if wantContent {
+				w.Header().Set(headerContentDigest, digestField(content, dw.body.Bytes()))
+			}
+			if wantRepr {
+				w.Header().Set(headerReprDigest, digestField(repr, dw.body.Bytes()))
+			}
+		}
+
+		w.WriteHeader(dw.code)
+		w.Write(dw.body.Bytes())
+	}), opts...)
+}
+
+// digestField returns the value of a Content-Digest or Repr-Digest header.
+func digestField(algorithm string, body []byte) string {
+	hash := digestFunc(algorithm)()
+	hash.Write(body)
+
+	field, _ := sf.SerializeDictionary(sf.Dictionary{{Key: algorithm, Value: sf.Item{Value: hash.Sum(nil)}}})
+
+	return field
+}
+
+// digestWriter is a ResponseWriter which buffers the status code and body.
+type digestWriter struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *digestWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.code, w.wroteHeader = code, true
+	}
+}
+
+func (w *digestWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
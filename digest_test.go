@@ -0,0 +1,143 @@
+package negotiator
+
+import (
+	"crypto/md5"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DigestSuite struct {
+	suite.Suite
+}
+
+func (s DigestSuite) TestContentDigest() {
+	n := setUpNegotiator(headerWantContentDigest, "sha-256=3, sha-512=10")
+
+	alg, ok := n.ContentDigest()
+	s.True(ok)
+	s.Equal("sha-512", alg)
+
+	_, ok = n.ReprDigest()
+	s.False(ok)
+}
+
+func (s DigestSuite) TestTie() {
+	alg, _ := setUpNegotiator(headerWantReprDigest, "sha-512=5, sha-256=5").ReprDigest()
+	s.Equal("sha-256", alg)
+}
+
+func (s DigestSuite) TestUnwanted() {
+	for _, val := range []string{"", "sha-256=0", "md5=10", "sha-256=11, sha-512=0.5", "sha-256=(1)", "sha-256=1,"} {
+		_, ok := setUpNegotiator(headerWantContentDigest, val).ContentDigest()
+		s.False(ok, val)
+	}
+}
+
+func (s DigestSuite) TestStrict() {
+	header := make(http.Header)
+	header.Set(headerWantContentDigest, "sha-256=1,")
+
+	s.Nil(New(header).Validate(headerWantContentDigest))
+	s.Equal(&ParseError{Header: headerWantContentDigest, Index: 1, Offset: 10, Reason: "trailing comma"}, New(header, WithStrict()).Validate(headerWantContentDigest))
+}
+
+func (s DigestSuite) TestLimits() {
+	header := make(http.Header)
+	header.Set(headerWantContentDigest, "sha-256=3, sha-512=10")
+
+	ignored := New(header, WithLimits(Limits{MaxBytes: 16, Action: LimitIgnore}))
+	s.Equal(New(nil).specs(headerWantContentDigest), ignored.specs(headerWantContentDigest))
+	s.Nil(ignored.Validate(headerWantContentDigest))
+
+	alg, ok := New(header, WithLimits(Limits{MaxBytes: 16})).ContentDigest()
+	s.True(ok)
+	s.Equal("sha-256", alg)
+
+	s.Equal(&LimitError{Header: headerWantContentDigest, Limit: "bytes", Max: 16},
+		New(header, WithLimits(Limits{MaxBytes: 16, Action: LimitReject})).Validate(headerWantContentDigest))
+}
+
+func (s DigestSuite) TestCachedFieldLines() {
+	cache := NewCache(8, false)
+
+	header := make(http.Header)
+	header.Add(headerWantContentDigest, "sha-256=3")
+	header.Add(headerWantContentDigest, "sha-512=10")
+
+	alg, _ := New(header, WithCache(cache)).ContentDigest()
+	s.Equal("sha-512", alg)
+
+	header = make(http.Header)
+	header.Add(headerWantContentDigest, "sha-256=3")
+
+	alg, _ = New(header, WithCache(cache)).ContentDigest()
+	s.Equal("sha-256", alg)
+}
+
+func (s DigestSuite) TestRegisterDigest() {
+	RegisterDigest("MD5", md5.New)
+	defer func() {
+		digests.Lock()
+		digests.names = digests.names[:2]
+		delete(digests.funcs, "md5")
+		digests.Unlock()
+	}()
+
+	s.Equal([]string{"sha-256", "sha-512", "md5"}, DigestAlgorithms())
+
+	alg, _ := setUpNegotiator(headerWantContentDigest, "md5=10, sha-256=1").ContentDigest()
+	s.Equal("md5", alg)
+}
+
+func (s DigestSuite) TestHandler() {
+	h := DigestHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerContentType, "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"hello": "world"}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(headerWantContentDigest, "sha-256=10")
+	req.Header.Set(headerWantReprDigest, "sha-512=3")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	// The example of RFC 9530 Appendix D.1.
+	s.Equal(http.StatusCreated, w.Code)
+	s.Equal(`{"hello": "world"}`, w.Body.String())
+	s.Equal("sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:", w.Header().Get(headerContentDigest))
+	s.Equal("sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:", w.Header().Get(headerReprDigest))
+}
+
+func (s DigestSuite) TestHandlerNotWanted() {
+	h := DigestHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("hello", w.Body.String())
+	s.Equal("", w.Header().Get(headerContentDigest))
+}
+
+func (s DigestSuite) TestHandlerNoContent() {
+	h := DigestHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	req.Header.Set(headerWantContentDigest, "sha-256=10")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	s.Equal(http.StatusNoContent, w.Code)
+	s.Equal("", w.Header().Get(headerContentDigest))
+}
+
+func TestDigest(t *testing.T) {
+	suite.Run(t, new(DigestSuite))
+}
@@ -0,0 +1,157 @@
+package negotiator
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	// SourceCookie means the decision came from a cookie.
+	SourceCookie Source = "cookie"
+	// SourcePath means the decision came from a prefix of the URL path, e.g.
+	// "/de/users".
+	SourcePath Source = "path"
+	// SourceDefault means no source decided and the default was used.
+	SourceDefault Source = "default"
+)
+
+// LanguageSource looks up the language requested by r. It returns the
+// requested language, which is validated against the available languages
+// afterwards, and the source of the language. An empty language means the
+// source doesn't decide.
+type LanguageSource func(r *http.Request, available []string) (language string, source Source)
+
+// LanguageQuery returns a LanguageSource which looks up the language in the
+// query parameter named param.
+func LanguageQuery(param string) LanguageSource {
+	return func(r *http.Request, available []string) (string, Source) {
+		return r.URL.Query().Get(param), SourceQuery
+	}
+}
+
+// LanguageCookie returns a LanguageSource which looks up the language in the
+// cookie named name.
+func LanguageCookie(name string) LanguageSource {
+	return func(r *http.Request, available []string) (string, Source) {
+		if cookie, err := r.Cookie(name); err == nil {
+			return cookie.Value, SourceCookie
+		}
+
+		return "", SourceCookie
+	}
+}
+
+// LanguagePathPrefix returns a LanguageSource which looks up the language in
+// the first segment of the URL path, e.g. "de" in "/de/users".
+func LanguagePathPrefix() LanguageSource {
+	return func(r *http.Request, available []string) (string, Source) {
+		segment := strings.TrimPrefix(r.URL.Path, "/")
+		if i := strings.Index(segment, "/"); i != -1 {
+			segment = segment[:i]
+		}
+
+		return segment, SourcePath
+	}
+}
+
+// LanguageFunc returns a LanguageSource which looks up the language with fn,
+// e.g. in a user profile, and reports source as its source.
+func LanguageFunc(source Source, fn func(r *http.Request) string) LanguageSource {
+	return func(r *http.Request, available []string) (string, Source) {
+		return fn(r), source
+	}
+}
+
+// LanguageAccept returns a LanguageSource which negotiates the language with
+// the HTTP Accept-Language header. A language range with a region such as
+// "de-AT" falls back to its primary language "de" if it's preferred over the
+// available languages matched as is, unless "de" is listed itself, e.g. with
+// q=0. It doesn't decide if the request has no Accept-Language header.
+func LanguageAccept() LanguageSource {
+	return func(r *http.Request, available []string) (string, Source) {
+		if strings.TrimSpace(strings.Join(r.Header[headerAcceptLanguage], "")) == "" {
+			return "", SourceAccept
+		}
+
+		n := FromRequest(r)
+
+		language := n.Language(available...)
+		q := n.quality(headerAcceptLanguage, language)
+
+		specs := n.specs(headerAcceptLanguage)
+
+		for _, spec := range specs {
+			if spec.q.float() <= q {
+				break
+			}
+
+			// A listed language has its own q, which Language took into
+			// account.
+			if fallback := availableLanguage(spec.val, available); fallback != "" && !specs.hasVal(fallback) {
+				return n.decide(headerAcceptLanguage, fallback), SourceAccept
+			}
+		}
+
+		return language, SourceAccept
+	}
+}
+
+// LanguageDecision represents the result of LanguageResolver.Resolve.
+type LanguageDecision struct {
+	// Language is the resolved language, as spelled in Available.
+	Language string
+	// Source is the source which decided.
+	Source Source
+}
+
+// LanguageResolver resolves the language of a request by asking its sources
+// in order. The first language which is available wins.
+type LanguageResolver struct {
+	// Available lists the available languages.
+	Available []string
+	// Sources lists the sources to ask, in order of precedence.
+	Sources []LanguageSource
+	// Default is the language used if no source decides.
+	Default string
+}
+
+// Resolve returns the language of r and the source which decided it.
+func (lr LanguageResolver) Resolve(r *http.Request) LanguageDecision {
+	for _, source := range lr.Sources {
+		language, src := source(r, lr.Available)
+
+		if language = lr.available(language); language != "" {
+			return LanguageDecision{Language: language, Source: src}
+		}
+	}
+
+	return LanguageDecision{Language: lr.Default, Source: SourceDefault}
+}
+
+// available returns the available language matching language.
+func (lr LanguageResolver) available(language string) string {
+	return availableLanguage(language, lr.Available)
+}
+
+// availableLanguage returns the language in available matching language. A
+// language with a region such as "de-AT" falls back to its primary language
+// "de".
+func availableLanguage(language string, available []string) string {
+	language = strings.TrimSpace(language)
+
+	for language != "" {
+		for _, a := range available {
+			if strings.EqualFold(a, language) {
+				return a
+			}
+		}
+
+		i := strings.LastIndex(language, "-")
+		if i == -1 {
+			break
+		}
+		language = language[:i]
+	}
+
+	return ""
+}
@@ -0,0 +1,115 @@
+package negotiator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type profileKey struct{}
+
+var languageResolver = LanguageResolver{
+	Available: []string{"en", "de", "pt-BR"},
+	Sources: []LanguageSource{
+		LanguageQuery("lang"),
+		LanguageCookie("lang"),
+		LanguagePathPrefix(),
+		LanguageFunc("profile", func(r *http.Request) string {
+			language, _ := r.Context().Value(profileKey{}).(string)
+			return language
+		}),
+		LanguageAccept(),
+	},
+	Default: "en",
+}
+
+func setUpLanguageRequest(target, acceptLanguage string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set(headerAcceptLanguage, acceptLanguage)
+
+	return req
+}
+
+type LanguageResolverSuite struct {
+	suite.Suite
+}
+
+func (s LanguageResolverSuite) TestQuery() {
+	req := setUpLanguageRequest("/de/users?lang=pt-br", "de")
+	req.AddCookie(&http.Cookie{Name: "lang", Value: "de"})
+
+	s.Equal(LanguageDecision{Language: "pt-BR", Source: SourceQuery}, languageResolver.Resolve(req))
+}
+
+func (s LanguageResolverSuite) TestCookie() {
+	req := setUpLanguageRequest("/users", "en")
+	req.AddCookie(&http.Cookie{Name: "lang", Value: "de"})
+
+	s.Equal(LanguageDecision{Language: "de", Source: SourceCookie}, languageResolver.Resolve(req))
+}
+
+func (s LanguageResolverSuite) TestUnavailableSkipped() {
+	req := setUpLanguageRequest("/users?lang=fr", "de")
+
+	s.Equal(LanguageDecision{Language: "de", Source: SourceAccept}, languageResolver.Resolve(req))
+}
+
+func (s LanguageResolverSuite) TestPathPrefix() {
+	req := setUpLanguageRequest("/de-AT/users", "en")
+
+	s.Equal(LanguageDecision{Language: "de", Source: SourcePath}, languageResolver.Resolve(req))
+}
+
+func (s LanguageResolverSuite) TestFunc() {
+	req := setUpLanguageRequest("/users", "en")
+	req = req.WithContext(context.WithValue(req.Context(), profileKey{}, "de"))
+
+	s.Equal(LanguageDecision{Language: "de", Source: "profile"}, languageResolver.Resolve(req))
+}
+
+func (s LanguageResolverSuite) TestAccept() {
+	req := setUpLanguageRequest("/users", "fr, pt-BR;q=0.8, en;q=0.5")
+
+	s.Equal(LanguageDecision{Language: "pt-BR", Source: SourceAccept}, languageResolver.Resolve(req))
+}
+
+func (s LanguageResolverSuite) TestDefault() {
+	req := setUpLanguageRequest("/users", "fr")
+
+	s.Equal(LanguageDecision{Language: "en", Source: SourceDefault}, languageResolver.Resolve(req))
+}
+
+func (s LanguageResolverSuite) TestAcceptFallback() {
+	req := setUpLanguageRequest("/users", "fr, de-AT;q=0.8, en;q=0.5")
+	n := NewFromRequest(req)
+	req = req.WithContext(NewContext(req.Context(), n))
+
+	s.Equal(LanguageDecision{Language: "de", Source: SourceAccept}, languageResolver.Resolve(req))
+
+	decision, _ := n.Decision(headerAcceptLanguage)
+	s.Equal("de", decision)
+
+	req = setUpLanguageRequest("/users", "de-AT;q=0.5, en")
+
+	s.Equal(LanguageDecision{Language: "en", Source: SourceAccept}, languageResolver.Resolve(req))
+
+	// The primary language is refused, or less preferred than en.
+	for _, accept := range []string{"de-AT, de;q=0, en;q=0.5", "de-AT, de;q=0.1, en;q=0.5"} {
+		req = setUpLanguageRequest("/users", accept)
+
+		s.Equal(LanguageDecision{Language: "en", Source: SourceAccept}, languageResolver.Resolve(req), accept)
+	}
+}
+
+func (s LanguageResolverSuite) TestDefaultWithoutAccept() {
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+
+	s.Equal(LanguageDecision{Language: "en", Source: SourceDefault}, languageResolver.Resolve(req))
+}
+
+func TestLanguageResolver(t *testing.T) {
+	suite.Run(t, new(LanguageResolverSuite))
+}
@@ -0,0 +1,61 @@
+package negotiator
+
+import (
+	"strconv"
+)
+
+// LimitAction defines what happens when a header exceeds Limits.
+type LimitAction int
+
+const (
+	// LimitTruncate parses the header only up to the exceeded limit.
+	// Elements with too many parameters are skipped.
+	LimitTruncate LimitAction = iota
+	// LimitIgnore treats the header as absent.
+	LimitIgnore
+	// LimitReject makes the header accept nothing, and Validate return a
+	// *LimitError.
+	LimitReject
+)
+
+// Limits bounds the work done parsing a header. A zero limit means no limit.
+type Limits struct {
+	// MaxBytes is the maximum length of a header value.
+	MaxBytes int
+	// MaxElements is the maximum number of elements in a header value.
+	MaxElements int
+	// MaxParams is the maximum number of parameters of an element.
+	MaxParams int
+	// Action defines what happens when a limit is exceeded.
+	Action LimitAction
+}
+
+// DefaultLimits are the Limits of a Negotiator unless changed by WithLimits.
+// They are far above what real user agents send.
+var DefaultLimits = Limits{
+	MaxBytes:    8192,
+	MaxElements: 128,
+	MaxParams:   16,
+	Action:      LimitTruncate,
+}
+
+// WithLimits sets the Limits of a Negotiator.
+func WithLimits(l Limits) Option {
+	return func(n *Negotiator) {
+		n.limits = l
+	}
+}
+
+// LimitError reports a header which exceeds Limits.
+type LimitError struct {
+	// Header is the name of the header.
+	Header string
+	// Limit is the exceeded limit: "bytes", "elements" or "params".
+	Limit string
+	// Max is the value of the exceeded limit.
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return "negotiator: " + e.Header + " header exceeds " + strconv.Itoa(e.Max) + " " + e.Limit
+}
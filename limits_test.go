@@ -0,0 +1,102 @@
+package negotiator
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func setUpLimitedNegotiator(val string, limits Limits) *Negotiator {
+	header := make(http.Header)
+	header.Set(headerAccept, val)
+
+	return New(header, WithLimits(limits))
+}
+
+type LimitsSuite struct {
+	suite.Suite
+}
+
+func (s LimitsSuite) TestDefaultLimits() {
+	n := setUpNegotiator(headerAccept, "text/html")
+
+	s.Equal(DefaultLimits, n.limits)
+}
+
+func (s LimitsSuite) TestTruncateBytes() {
+	n := setUpLimitedNegotiator("text/html, application/json, image/png", Limits{MaxBytes: 30})
+
+	s.Equal("application/json", n.Type("application/json"))
+	s.Equal("", n.Type("image/png"))
+	s.Nil(n.Validate())
+}
+
+func (s LimitsSuite) TestTruncateElements() {
+	n := setUpLimitedNegotiator("text/html,, application/json, image/png", Limits{MaxElements: 2})
+
+	s.Equal("application/json", n.Type("application/json"))
+	s.Equal("", n.Type("image/png"))
+}
+
+func (s LimitsSuite) TestTruncateParams() {
+	n := setUpLimitedNegotiator("text/html;a=1;b=2;c=3, application/json", Limits{MaxParams: 2})
+
+	s.Equal("", n.Type("text/html"))
+	s.Equal("application/json", n.Type("application/json"))
+}
+
+func (s LimitsSuite) TestIgnore() {
+	n := setUpLimitedNegotiator("text/html, application/json, image/png", Limits{MaxElements: 2, Action: LimitIgnore})
+
+	s.Equal("image/png", n.Type("image/png"))
+	s.Nil(n.Validate())
+}
+
+func (s LimitsSuite) TestReject() {
+	n := setUpLimitedNegotiator("text/html, application/json, image/png", Limits{MaxBytes: 16, Action: LimitReject})
+
+	s.Equal("", n.Type("text/html"))
+	s.Equal(&LimitError{Header: headerAccept, Limit: "bytes", Max: 16}, n.Validate())
+	s.Nil(n.Validate(headerAcceptLanguage))
+	s.EqualError(n.Validate(), "negotiator: Accept header exceeds 16 bytes")
+}
+
+func (s LimitsSuite) TestRejectCached() {
+	cache := NewCache(8, false)
+	header := make(http.Header)
+	header.Set(headerAccept, "text/html;a=1;b=2")
+	limits := WithLimits(Limits{MaxParams: 1, Action: LimitReject})
+
+	s.NotNil(New(header, WithCache(cache), limits).Validate())
+	s.NotNil(New(header, WithCache(cache), limits).Validate())
+	s.Equal(uint64(1), cache.Stats().Hits)
+}
+
+func (s LimitsSuite) TestHostileHeader() {
+	n := setUpNegotiator(headerAccept, strings.Repeat("*/*;q=0.5, text/*;q=0.5, ", 4096))
+
+	s.Equal("application/json", n.Type("application/json", "text/html"))
+	s.Len(n.specs(headerAccept), DefaultLimits.MaxElements)
+}
+
+func TestLimits(t *testing.T) {
+	suite.Run(t, new(LimitsSuite))
+}
+
+func BenchmarkHostileHeader(b *testing.B) {
+	header := make(http.Header)
+	header.Set(headerAccept, strings.Repeat("*/*;q=0.5, text/*;q=0.5, ", 4096))
+	offers := make([]string, 64)
+
+	for i := range offers {
+		offers[i] = "application/x-offer-" + strings.Repeat("x", i)
+	}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		New(header, WithLimits(Limits{})).Type(offers...)
+	}
+}
@@ -0,0 +1,177 @@
+package negotiator
+
+import (
+	"strings"
+)
+
+// parseMediaRangeParams parses the parameters of a media range. Parameters
+// before q belong to the media range, q and the parameters after it are
+// accept parameters, of which only q is used. Unlike other headers, a media
+// range with q=0 is kept, as it excludes less specific ranges.
+func (p headerParser) parseMediaRangeParams(s spec, params string) (spec, bool) {
+	for start := 0; start < len(params); {
+		end := strings.IndexByte(params[start:], ';')
+		if end == -1 {
+			end = len(params)
+		} else {
+			end += start
+		}
+
+		if name, val := splitParam(params[start:end]); name == "q" {
+			q, valid := parseLenientQValue(val)
+			if !valid {
+				return s, false
+			}
+			if q > p.defaultQ {
+				q = p.defaultQ
+			}
+			s.q = q
+
+			params = params[:start]
+			break
+		}
+
+		start = end + 1
+	}
+
+	s.params = formatToken(strings.TrimRight(params, " ;"))
+
+	return s, true
+}
+
+// splitParam splits a parameter such as "q=0.5" into its lowercase name and
+// its value without quotes.
+func splitParam(param string) (name, val string) {
+	eq := strings.IndexByte(param, '=')
+	if eq == -1 {
+		return formatToken(param), ""
+	}
+
+	name = formatToken(param[:eq])
+	val = strings.TrimSpace(param[eq+1:])
+
+	if len(val) >= 2 && val[0] == '"' && val[len(val)-1] == '"' {
+		val = val[1 : len(val)-1]
+	}
+
+	return
+}
+
+// specificity returns how specific s is: "*/*" is less specific than
+// "text/*", which is less specific than "text/plain", which is less specific
+// than "text/plain;format=flowed". The wildcard "*" of other headers is less
+// specific than any value.
+func (s spec) specificity() int {
+	switch {
+	case s.val == "*/*" || s.val == "*":
+		return 0
+	case strings.HasSuffix(s.val, "/*"):
+		return 1
+	case s.params == "":
+		return 2
+	}
+
+	return 3 + strings.Count(s.params, ";")
+}
+
+// matchesMediaType reports whether the media range of s matches mt.
+func (s spec) matchesMediaType(mt mediaType) bool {
+	slash := strings.IndexByte(s.val, '/')
+
+	switch {
+	case s.val == "*/*":
+		return true
+	case slash == -1:
+		if mt.subtype != "" || !strings.EqualFold(s.val, mt.typ) {
+			return false
+		}
+	case !strings.EqualFold(s.val[:slash], mt.typ) || mt.subtype == "":
+		return false
+	case s.val[slash+1:] == "*":
+		return true
+	case !strings.EqualFold(s.val[slash+1:], mt.subtype):
+		return false
+	}
+
+	for rest := s.params; len(rest) > 0; {
+		var param string
+
+		if i := strings.IndexByte(rest, ';'); i != -1 {
+			param, rest = rest[:i], rest[i+1:]
+		} else {
+			param, rest = rest, ""
+		}
+
+		if !hasParam(mt.params, param) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasParam reports whether params contains the parameter param, comparing
+// names and values case-insensitively.
+func hasParam(params, param string) bool {
+	name, val := splitParam(param)
+
+	for rest := params; len(rest) > 0; {
+		var p string
+
+		if i := strings.IndexByte(rest, ';'); i != -1 {
+			p, rest = rest[:i], rest[i+1:]
+		} else {
+			p, rest = rest, ""
+		}
+
+		if n, v := splitParam(p); n == name && strings.EqualFold(v, val) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mediaType is a media type split into its type, subtype and parameters,
+// such as "text", "plain" and " format=flowed" for
+// "text/plain; format=flowed". A value without a slash has no subtype.
+type mediaType struct {
+	typ     string
+	subtype string
+	params  string
+}
+
+// splitMediaType splits a media type such as "text/plain; format=flowed" into
+// its parts.
+func splitMediaType(s string) (mt mediaType) {
+	if i := strings.IndexByte(s, ';'); i != -1 {
+		s, mt.params = s[:i], s[i+1:]
+	}
+
+	mt.typ = strings.TrimSpace(s)
+
+	if i := strings.IndexByte(mt.typ, '/'); i != -1 {
+		mt.typ, mt.subtype = strings.TrimSpace(mt.typ[:i]), strings.TrimSpace(mt.typ[i+1:])
+	}
+
+	return
+}
+
+// mediaRangeMatch returns the most specific media range in specs which
+// matches mt and its q, as RFC 9110 section 12.5.1 defines. Among equally
+// specific ranges the highest q wins.
+func mediaRangeMatch(mt mediaType, specs specs) (q qvalue, m spec) {
+	best := -1
+
+	for _, spec := range specs {
+		if !spec.matchesMediaType(mt) {
+			continue
+		}
+
+		if specificity := spec.specificity(); specificity > best || (specificity == best && spec.q > q) {
+			best, q, m = specificity, spec.q, spec
+		}
+	}
+
+	return
+}
@@ -0,0 +1,69 @@
+package negotiator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MediaRangeSuite struct {
+	suite.Suite
+}
+
+// TestRFC9110Example ports the example of RFC 9110 section 12.5.1.
+func (s MediaRangeSuite) TestRFC9110Example() {
+	n := setUpNegotiator(headerAccept, "text/*;q=0.3, text/plain;q=0.7, text/plain;format=flowed, "+
+		"text/plain;format=fixed;q=0.4, */*;q=0.5")
+
+	for offer, q := range map[string]float64{
+		"text/plain;format=flowed": 1,
+		"text/plain":               0.7,
+		"text/html":                0.3,
+		"image/jpeg":               0.5,
+		"text/plain;format=fixed":  0.4,
+		// The RFC lists 0.7, left over from the text/html based example
+		// of RFC 7231, but only text/* matches.
+		"text/html;level=3": 0.3,
+	} {
+		s.Equal(q, n.quality(headerAccept, offer), offer)
+	}
+}
+
+func (s MediaRangeSuite) TestMostSpecificWins() {
+	n := setUpNegotiator(headerAccept, "text/*;q=1, text/html;q=0.1")
+
+	s.Equal("text/plain", n.Type("text/html", "text/plain"))
+	s.Equal("text/html", n.Type("text/html"))
+	s.Equal("text/plain", n.SelectType(NewOfferSet("text/html", "text/plain")))
+}
+
+func (s MediaRangeSuite) TestExcludedByQZero() {
+	n := setUpNegotiator(headerAccept, "text/*, text/html;q=0")
+
+	s.Equal("", n.Type("text/html"))
+	s.Equal("text/plain", n.Type("text/html", "text/plain"))
+	s.Equal("", n.SelectType(NewOfferSet("text/html")))
+}
+
+func (s MediaRangeSuite) TestOnlyExcluded() {
+	n := setUpNegotiator(headerAccept, "text/html;q=0")
+
+	s.Equal("", n.Type())
+	s.Equal("", n.SelectType(NewOfferSet()))
+}
+
+func (s MediaRangeSuite) TestParams() {
+	n := setUpNegotiator(headerAccept, `text/plain; Format="Flowed"; q=0.5; ext=1, text/html;level=1`)
+	specs := n.specs(headerAccept)
+
+	s.Len(specs, 2)
+	s.Equal(spec{val: "text/html", q: maxQ, params: "level=1", index: 1}, specs[0])
+	s.Equal(spec{val: "text/plain", q: 500, params: `format="flowed"`}, specs[1])
+
+	s.Equal("text/plain;format=flowed", n.Type("text/plain", "text/plain;format=flowed"))
+	s.Equal("", n.Type("text/html;level=2"))
+}
+
+func TestMediaRange(t *testing.T) {
+	suite.Run(t, new(MediaRangeSuite))
+}
@@ -0,0 +1,130 @@
+package negotiator
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	headerAcceptDatetime  = "Accept-Datetime"
+	headerMementoDatetime = "Memento-Datetime"
+)
+
+// Memento represents an archived version of a resource, as defined in
+// RFC 7089.
+type Memento struct {
+	// URI is the URI of the memento.
+	URI string
+	// Datetime is the time the memento was archived.
+	Datetime time.Time
+}
+
+// MementoLinks are the URIs related to a memento, which are sent in the HTTP
+// Link header. Empty URIs are omitted.
+type MementoLinks struct {
+	// Original is the URI of the original resource.
+	Original string
+	// TimeGate is the URI of the TimeGate of the original resource.
+	TimeGate string
+	// TimeMap is the URI of the TimeMap of the original resource.
+	TimeMap string
+}
+
+// AcceptDatetime returns the time of the HTTP Accept-Datetime header. ok is
+// false if the header is absent or malformed, in which case err is a
+// *ParseError, so that a TimeGate can respond with 400 Bad Request. In strict
+// mode, only the IMF-fixdate format required by RFC 7089 is accepted.
+func (n *Negotiator) AcceptDatetime() (t time.Time, ok bool, err error) {
+	val := strings.TrimSpace(n.header.Get(headerAcceptDatetime))
+	if val == "" {
+		return
+	}
+
+	if n.strict {
+		t, err = time.Parse(http.TimeFormat, val)
+	} else {
+		t, err = http.ParseTime(val)
+	}
+
+	if err != nil {
+		return time.Time{}, false, &ParseError{Header: headerAcceptDatetime, Reason: "invalid HTTP-date"}
+	}
+
+	return t, true, nil
+}
+
+// Memento returns the memento closest to the time of the HTTP
+// Accept-Datetime header, preferring the earlier one of two equally close
+// mementos. Without the header, the most recent memento is returned. ok is
+// false if there are no mementos or if the header is malformed, in which case
+// err is the error of AcceptDatetime.
+func (n *Negotiator) Memento(mementos []Memento) (memento Memento, ok bool, err error) {
+	t, accepted, err := n.AcceptDatetime()
+	if err != nil || len(mementos) == 0 {
+		return
+	}
+
+	best := 0
+
+	for i := 1; i < len(mementos); i++ {
+		m := mementos[i]
+
+		if !accepted {
+			if m.Datetime.After(mementos[best].Datetime) {
+				best = i
+			}
+			continue
+		}
+
+		d, bestD := distance(m.Datetime, t), distance(mementos[best].Datetime, t)
+		if d < bestD || (d == bestD && m.Datetime.Before(mementos[best].Datetime)) {
+			best = i
+		}
+	}
+
+	return mementos[best], true, nil
+}
+
+// SetTimeGateHeaders sets the headers of a TimeGate response to h: the HTTP
+// Link headers of links and "Vary: accept-datetime". The TimeGate redirects
+// to the selected memento with the Location header.
+func SetTimeGateHeaders(h http.Header, links MementoLinks) {
+	addMementoLinks(h, links)
+	h.Add(headerVary, strings.ToLower(headerAcceptDatetime))
+}
+
+// SetMementoHeaders sets the headers of a memento response to h: the HTTP
+// Memento-Datetime header of m and the HTTP Link headers of links and m.
+func SetMementoHeaders(h http.Header, m Memento, links MementoLinks) {
+	h.Set(headerMementoDatetime, m.Datetime.UTC().Format(http.TimeFormat))
+
+	addMementoLinks(h, links)
+	if m.URI != "" {
+		h.Add(headerLink, mementoLink(m))
+	}
+}
+
+func addMementoLinks(h http.Header, links MementoLinks) {
+	if links.Original != "" {
+		h.Add(headerLink, "<"+links.Original+`>; rel="original"`)
+	}
+	if links.TimeGate != "" {
+		h.Add(headerLink, "<"+links.TimeGate+`>; rel="timegate"`)
+	}
+	if links.TimeMap != "" {
+		h.Add(headerLink, "<"+links.TimeMap+`>; rel="timemap"; type="application/link-format"`)
+	}
+}
+
+func mementoLink(m Memento) string {
+	return "<" + m.URI + `>; rel="memento"; datetime="` + m.Datetime.UTC().Format(http.TimeFormat) + `"`
+}
+
+func distance(a, b time.Time) time.Duration {
+	if a.Before(b) {
+		return b.Sub(a)
+	}
+
+	return a.Sub(b)
+}
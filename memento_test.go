@@ -0,0 +1,125 @@
+package negotiator
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var mementos = []Memento{
+	{URI: "/archive/2001/page", Datetime: time.Date(2001, 5, 1, 0, 0, 0, 0, time.UTC)},
+	{URI: "/archive/2010/page", Datetime: time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)},
+	{URI: "/archive/2005/page", Datetime: time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC)},
+}
+
+type MementoSuite struct {
+	suite.Suite
+}
+
+func (s MementoSuite) TestAcceptDatetime() {
+	n := setUpNegotiator(headerAcceptDatetime, "Thu, 31 May 2007 20:35:00 GMT")
+
+	t, ok, err := n.AcceptDatetime()
+	s.True(ok)
+	s.Nil(err)
+	s.Equal(time.Date(2007, 5, 31, 20, 35, 0, 0, time.UTC), t)
+}
+
+func (s MementoSuite) TestAcceptDatetimeInvalid() {
+	_, ok, err := setUpNegotiator(headerAcceptDatetime, "yesterday").AcceptDatetime()
+	s.False(ok)
+	s.Equal(&ParseError{Header: headerAcceptDatetime, Reason: "invalid HTTP-date"}, err)
+
+	_, ok, err = New(nil).AcceptDatetime()
+	s.False(ok)
+	s.Nil(err)
+}
+
+func (s MementoSuite) TestAcceptDatetimeStrict() {
+	header := make(http.Header)
+	header.Set(headerAcceptDatetime, "Thursday, 31-May-07 20:35:00 GMT")
+
+	_, ok, _ := New(header).AcceptDatetime()
+	s.True(ok)
+
+	_, ok, err := New(header, WithStrict()).AcceptDatetime()
+	s.False(ok)
+	s.NotNil(err)
+}
+
+func (s MementoSuite) TestClosest() {
+	n := setUpNegotiator(headerAcceptDatetime, "Thu, 31 May 2007 20:35:00 GMT")
+
+	m, ok, err := n.Memento(mementos)
+	s.True(ok)
+	s.Nil(err)
+	s.Equal(mementos[2], m)
+
+	n = setUpNegotiator(headerAcceptDatetime, "Sat, 01 Jan 2000 00:00:00 GMT")
+
+	m, _, _ = n.Memento(mementos)
+	s.Equal(mementos[0], m)
+}
+
+func (s MementoSuite) TestEquallyClose() {
+	n := setUpNegotiator(headerAcceptDatetime, "Sat, 01 Jan 2005 00:00:00 GMT")
+	equal := []Memento{
+		{URI: "/after", Datetime: time.Date(2005, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{URI: "/before", Datetime: time.Date(2004, 12, 31, 0, 0, 0, 0, time.UTC)},
+	}
+
+	m, _, _ := n.Memento(equal)
+	s.Equal("/before", m.URI)
+}
+
+func (s MementoSuite) TestMostRecent() {
+	m, ok, _ := New(nil).Memento(mementos)
+	s.True(ok)
+	s.Equal(mementos[1], m)
+
+	_, ok, _ = New(nil).Memento(nil)
+	s.False(ok)
+}
+
+func (s MementoSuite) TestMalformed() {
+	_, ok, err := setUpNegotiator(headerAcceptDatetime, "yesterday").Memento(mementos)
+
+	s.False(ok)
+	s.EqualError(err, "negotiator: malformed Accept-Datetime header at element 0 (offset 0): invalid HTTP-date")
+}
+
+func (s MementoSuite) TestTimeGateHeaders() {
+	h := make(http.Header)
+	SetTimeGateHeaders(h, MementoLinks{Original: "/page", TimeMap: "/timemap/page"})
+
+	s.Equal([]string{
+		`</page>; rel="original"`,
+		`</timemap/page>; rel="timemap"; type="application/link-format"`,
+	}, h[headerLink])
+	s.Equal("accept-datetime", h.Get(headerVary))
+	s.Equal("", h.Get(headerMementoDatetime))
+}
+
+func (s MementoSuite) TestMementoHeaders() {
+	h := make(http.Header)
+	SetMementoHeaders(h, mementos[2], MementoLinks{Original: "/page", TimeGate: "/timegate/page"})
+
+	s.Equal("Sat, 01 Jan 2005 00:00:00 GMT", h.Get(headerMementoDatetime))
+	s.Equal([]string{
+		`</page>; rel="original"`,
+		`</timegate/page>; rel="timegate"`,
+		`</archive/2005/page>; rel="memento"; datetime="Sat, 01 Jan 2005 00:00:00 GMT"`,
+	}, h[headerLink])
+	s.Equal("", h.Get(headerVary))
+
+	h = make(http.Header)
+	SetMementoHeaders(h, Memento{Datetime: mementos[0].Datetime}, MementoLinks{TimeMap: "/timemap/page"})
+
+	s.Equal([]string{`</timemap/page>; rel="timemap"; type="application/link-format"`}, h[headerLink])
+}
+
+func TestMemento(t *testing.T) {
+	suite.Run(t, new(MementoSuite))
+}
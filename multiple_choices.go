@@ -0,0 +1,112 @@
+package negotiator
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"strings"
+)
+
+const (
+	headerLink        = "Link"
+	headerContentType = "Content-Type"
+)
+
+// ChooseVariant returns the variant with the highest overall quality, as
+// computed by SelectVariant. ok is false if no variant is acceptable or if
+// the choice is ambiguous because several variants share the highest overall
+// quality. In both cases the caller can respond with MultipleChoices.
+func (n *Negotiator) ChooseVariant(variants []Variant) (variant Variant, ok bool) {
+	variant, q, ok := n.SelectVariant(variants)
+	if !ok {
+		return
+	}
+
+	for _, v := range variants {
+		if v.URI != variant.URI {
+			if _, otherQ, _ := n.SelectVariant([]Variant{v}); otherQ == q {
+				return Variant{}, false
+			}
+		}
+	}
+
+	return
+}
+
+type representation struct {
+	URI      string `json:"uri"`
+	Type     string `json:"type,omitempty"`
+	Charset  string `json:"charset,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// MultipleChoices responds to r with 300 Multiple Choices, listing the
+// available variants in the response body and in a Link header with
+// rel="alternate" for each variant. The body is either JSON or HTML,
+// whichever the client prefers.
+func MultipleChoices(w http.ResponseWriter, r *http.Request, variants []Variant) {
+	h := w.Header()
+
+	for _, v := range variants {
+		h.Add(headerLink, alternateLink(v))
+	}
+
+	h.Add(headerVary, headerAccept)
+
+	if FromRequest(r).negotiate(headerAccept, "application/json", "text/html") == "text/html" {
+		h.Set(headerContentType, "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusMultipleChoices)
+		w.Write([]byte(alternatesHTML(variants)))
+		return
+	}
+
+	reps := make([]representation, len(variants))
+	for i, v := range variants {
+		reps[i] = representation{URI: v.URI, Type: v.Type, Charset: v.Charset, Language: v.Language}
+	}
+
+	h.Set(headerContentType, "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusMultipleChoices)
+	json.NewEncoder(w).Encode(map[string][]representation{"alternates": reps})
+}
+
+func alternateLink(v Variant) string {
+	link := "<" + v.URI + `>; rel="alternate"`
+
+	if v.Type != "" {
+		link += `; type="` + v.Type + `"`
+	}
+	if v.Language != "" {
+		link += `; hreflang="` + v.Language + `"`
+	}
+
+	return link
+}
+
+func alternatesHTML(variants []Variant) string {
+	var b strings.Builder
+
+	b.WriteString("<!DOCTYPE html>\n<title>Multiple Choices</title>\n<ul>\n")
+
+	for _, v := range variants {
+		uri := html.EscapeString(v.URI)
+		b.WriteString(`<li><a href="` + uri + `">` + uri + "</a>")
+
+		var attrs []string
+		for _, attr := range []string{v.Type, v.Charset, v.Language} {
+			if attr != "" {
+				attrs = append(attrs, html.EscapeString(attr))
+			}
+		}
+
+		if len(attrs) > 0 {
+			b.WriteString(" (" + strings.Join(attrs, ", ") + ")")
+		}
+
+		b.WriteString("</li>\n")
+	}
+
+	b.WriteString("</ul>\n")
+
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package negotiator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var reportVariants = []Variant{
+	{URI: "/report.en.html", Type: "text/html", Language: "en"},
+	{URI: "/report.de.html", Type: "text/html", Language: "de"},
+	{URI: "/report.json", Type: "application/json"},
+}
+
+type MultipleChoicesSuite struct {
+	suite.Suite
+}
+
+func (s MultipleChoicesSuite) TestChooseVariant() {
+	n := setUpTCNNegotiator("", "text/html", "de")
+	variant, ok := n.ChooseVariant(reportVariants)
+
+	s.True(ok)
+	s.Equal("/report.de.html", variant.URI)
+}
+
+func (s MultipleChoicesSuite) TestChooseVariantAmbiguous() {
+	n := setUpTCNNegotiator("", "text/html", "")
+	_, ok := n.ChooseVariant(reportVariants)
+
+	s.False(ok)
+}
+
+func (s MultipleChoicesSuite) TestChooseVariantNotAcceptable() {
+	n := setUpTCNNegotiator("", "image/png", "")
+	_, ok := n.ChooseVariant(reportVariants)
+
+	s.False(ok)
+}
+
+func (s MultipleChoicesSuite) TestJSON() {
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set(headerAccept, "application/json")
+	res := httptest.NewRecorder()
+
+	MultipleChoices(res, req, reportVariants)
+
+	s.Equal(http.StatusMultipleChoices, res.Code)
+	s.Equal("application/json; charset=utf-8", res.Header().Get(headerContentType))
+	s.Equal([]string{
+		`</report.en.html>; rel="alternate"; type="text/html"; hreflang="en"`,
+		`</report.de.html>; rel="alternate"; type="text/html"; hreflang="de"`,
+		`</report.json>; rel="alternate"; type="application/json"`,
+	}, res.Header()[headerLink])
+	s.JSONEq(`{"alternates": [
+		{"uri": "/report.en.html", "type": "text/html", "language": "en"},
+		{"uri": "/report.de.html", "type": "text/html", "language": "de"},
+		{"uri": "/report.json", "type": "application/json"}
+	]}`, res.Body.String())
+}
+
+func (s MultipleChoicesSuite) TestHTML() {
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set(headerAccept, "text/html, */*;q=0.8")
+	res := httptest.NewRecorder()
+
+	MultipleChoices(res, req, reportVariants)
+
+	s.Equal(http.StatusMultipleChoices, res.Code)
+	s.Equal("text/html; charset=utf-8", res.Header().Get(headerContentType))
+	s.Contains(res.Body.String(), `<li><a href="/report.de.html">/report.de.html</a> (text/html, de)</li>`)
+}
+
+func TestMultipleChoices(t *testing.T) {
+	suite.Run(t, new(MultipleChoicesSuite))
+}
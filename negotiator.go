@@ -2,7 +2,9 @@ package negotiator
 
 import (
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 )
 
 const (
@@ -12,9 +14,22 @@ const (
 	headerAcceptCharset  = "Accept-Charset"
 )
 
+type contextKey int
+
+const (
+	versionKey contextKey = iota
+	overrideKey
+	negotiatorKey
+)
+
 type spec struct {
 	val string
-	q   float64
+	q   qvalue
+	// params are the media type parameters of a media range, such as
+	// "format=flowed" in "text/plain;format=flowed".
+	params string
+	// index is the position of the spec in the header.
+	index int
 }
 
 // Specs represents []Spec.
@@ -32,27 +47,37 @@ func (ss specs) Swap(i, j int) {
 
 // Less is to impelement sort.Interface for Specs.
 func (ss specs) Less(i, j int) bool {
-	if ss[i].q > ss[j].q {
-		return true
+	return ss[i].before(ss[j])
+}
+
+// before reports whether s sorts before o: higher q first, and more
+// specific values first among equal q.
+func (s spec) before(o spec) bool {
+	if s.q != o.q {
+		return s.q > o.q
 	}
-	if ss[i].q == ss[j].q {
-		if ss[i].val == "*" || strings.HasSuffix(ss[i].val, "/*") {
-			return true
-		}
 
-		if ss[j].val == "*" || strings.HasSuffix(ss[j].val, "/*") {
-			return false
-		}
+	return s.specificity() > o.specificity()
+}
 
-		return i < j
+// sort sorts ss stably. Short lists, i.e. almost all real headers, are
+// sorted in place without allocating.
+func (ss specs) sort() {
+	if len(ss) > 16 {
+		sort.Stable(ss)
+		return
 	}
 
-	return false
+	for i := 1; i < len(ss); i++ {
+		for j := i; j > 0 && ss[j].before(ss[j-1]); j-- {
+			ss[j], ss[j-1] = ss[j-1], ss[j]
+		}
+	}
 }
 
 func (ss specs) hasVal(val string) bool {
 	for _, spec := range ss {
-		if spec.val == val {
+		if strings.EqualFold(spec.val, val) {
 			return true
 		}
 	}
@@ -60,40 +85,157 @@ func (ss specs) hasVal(val string) bool {
 	return false
 }
 
-// Negotiator repensents the HTTP negotiator.
+// Negotiator repensents the HTTP negotiator. It parses each header lazily
+// once and is safe for concurrent use.
 type Negotiator struct {
-	header http.Header
+	header  http.Header
+	request *http.Request
+
+	cache    *Cache
+	limits   Limits
+	strict   bool
+	tieBreak TieBreak
+
+	mu     sync.RWMutex
+	parsed map[string]parsedHeader
+	prefs  *Preferences
+
+	decisionsMu sync.Mutex
+	decisions   map[string]string
+	hints       []string
+}
+
+type parsedHeader struct {
+	specs specs
+	err   error
+}
+
+// Option configures a Negotiator.
+type Option func(*Negotiator)
+
+// WithCache makes a Negotiator look up parsed header values in c before
+// parsing them.
+func WithCache(c *Cache) Option {
+	return func(n *Negotiator) {
+		n.cache = c
+	}
 }
 
 // New creates an instance of Negotiator.
-func New(header http.Header) *Negotiator {
-	return &Negotiator{header}
+func New(header http.Header, opts ...Option) *Negotiator {
+	n := &Negotiator{header: header, limits: DefaultLimits}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
 }
 
 // Type returns the most preferred content type from the HTTP Accept header.
 // If nothing accepted, then empty string is returned.
 func (n *Negotiator) Type(offers ...string) (bestOffer string) {
-	parser := newHeaderParser(n.header, true)
-	return parser.selectOffer(offers, parser.parse(headerAccept))
+	return n.selectOffer(headerAccept, offers)
 }
 
 // Language returns the most preferred language from the HTTP Accept-Language
 // header. If nothing accepted, then empty string is returned.
 func (n *Negotiator) Language(offers ...string) (bestOffer string) {
-	parser := newHeaderParser(n.header, false)
-	return parser.selectOffer(offers, parser.parse(headerAcceptLanguage))
+	return n.selectOffer(headerAcceptLanguage, offers)
 }
 
 // Encoding returns the most preferred encoding from the HTTP Accept-Encoding
 // header. If nothing accepted, then empty string is returned.
 func (n *Negotiator) Encoding(offers ...string) (bestOffer string) {
-	parser := newHeaderParser(n.header, false)
-	return parser.selectOffer(offers, parser.parse(headerAcceptEncoding))
+	return n.selectOffer(headerAcceptEncoding, offers)
 }
 
 // Charset returns the most preferred charset from the HTTP Accept-Charset
 // header. If nothing accepted, then empty string is returned.
 func (n *Negotiator) Charset(offers ...string) (bestOffer string) {
-	parser := newHeaderParser(n.header, false)
-	return parser.selectOffer(offers, parser.parse(headerAcceptCharset))
+	return n.selectOffer(headerAcceptCharset, offers)
+}
+
+// Validate parses the given headers, by default Accept, Accept-Language,
+// Accept-Encoding and Accept-Charset, and returns the first error found.
+func (n *Negotiator) Validate(headerNames ...string) error {
+	if len(headerNames) == 0 {
+		headerNames = []string{headerAccept, headerAcceptLanguage, headerAcceptEncoding, headerAcceptCharset}
+	}
+
+	for _, headerName := range headerNames {
+		if _, err := n.parse(headerName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *Negotiator) parser(headerName string) headerParser {
+	parser := headerParser{
+		header:        n.header,
+		hasSlashVal:   headerName == headerAccept,
+		limits:        n.limits,
+		strict:        n.strict,
+		tieBreak:      n.tieBreak,
+		caseSensitive: headerName == headerAcceptProfile,
+		keepExcluded:  headerName == headerAcceptLanguage,
+	}
+	parser.init()
+
+	return parser
+}
+
+func (n *Negotiator) selectOffer(headerName string, offers []string) string {
+	return n.decide(headerName, n.negotiate(headerName, offers...))
+}
+
+// quality returns the quality of offer in the given header, or 0 if offer is
+// not acceptable.
+func (n *Negotiator) quality(headerName, offer string) float64 {
+	return n.parser(headerName).quality(offer, n.specs(headerName)).float()
+}
+
+// specs returns the parsed specs of the given header. The returned specs must
+// not be modified.
+func (n *Negotiator) specs(headerName string) specs {
+	ss, _ := n.parse(headerName)
+	return ss
+}
+
+// parse parses the given header on first use and memoizes the result.
+func (n *Negotiator) parse(headerName string) (specs, error) {
+	n.mu.RLock()
+	parsed, ok := n.parsed[headerName]
+	n.mu.RUnlock()
+
+	if ok {
+		return parsed.specs, parsed.err
+	}
+
+	parser := n.parser(headerName)
+	if n.cache != nil {
+		parsed.specs, parsed.err = n.cache.specs(parser, headerName, n.fieldLines(headerName), func() (specs, error) {
+			return parser.parseHeader(headerName)
+		})
+	} else {
+		parsed.specs, parsed.err = parser.parseHeader(headerName)
+	}
+
+	n.mu.Lock()
+	if n.parsed == nil {
+		n.parsed = make(map[string]parsedHeader)
+	}
+	n.parsed[headerName] = parsed
+	n.mu.Unlock()
+
+	return parsed.specs, parsed.err
+}
+
+// fieldLines returns all the field lines of the given header joined with a
+// newline, which can't occur in a field value. It keys the header in a
+// Cache, as some headers are parsed from all their field lines.
+func (n *Negotiator) fieldLines(headerName string) string {
+	return strings.Join(n.header[http.CanonicalHeaderKey(headerName)], "\n")
 }
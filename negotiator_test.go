@@ -11,7 +11,7 @@ import (
 
 func equalSpec(assert *assert.Assertions, spec spec, val string, q float64) {
 	assert.Equal(val, spec.val)
-	assert.Equal(q, spec.q)
+	assert.Equal(q, spec.q.float())
 }
 
 func setUpNegotiator(header, val string) *Negotiator {
@@ -120,6 +120,12 @@ func (s LanguageSuite) TestFirstMatchAllAsterisk() {
 	s.Equal("en", n.Language("en", "ko", "zh"))
 }
 
+func (s LanguageSuite) TestExcludedByQZero() {
+	n := setUpNegotiator(headerAcceptLanguage, "*, ko;q=0")
+	s.Equal("en", n.Language("ko", "en"))
+	s.Equal("", n.Language("ko"))
+}
+
 func TestLanguage(t *testing.T) {
 	suite.Run(t, new(LanguageSuite))
 }
@@ -216,3 +222,69 @@ func (s CharsetSuite) TestHighOrderPreferred() {
 func TestCharset(t *testing.T) {
 	suite.Run(t, new(CharsetSuite))
 }
+
+func TestConcurrentNegotiation(t *testing.T) {
+	n := setUpNegotiator(headerAccept, "text/html, application/*;q=0.9, image/jpeg;q=0.8")
+	done := make(chan string)
+
+	for i := 0; i < 8; i++ {
+		go func() {
+			done <- n.Type("application/json", "image/jpeg")
+		}()
+	}
+
+	for i := 0; i < 8; i++ {
+		assert.Equal(t, "application/json", <-done)
+	}
+}
+
+func setUpBrowserHeader() http.Header {
+	header := make(http.Header)
+	header.Set(headerAccept, "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8")
+	header.Set(headerAcceptLanguage, "en-US,en;q=0.9,de;q=0.8")
+	header.Set(headerAcceptEncoding, "gzip, deflate, br")
+	header.Set(headerAcceptCharset, "utf-8, iso-8859-1;q=0.5")
+
+	return header
+}
+
+func negotiateAll(n *Negotiator) {
+	n.Type("application/json", "text/html")
+	n.Type("text/html", "text/plain")
+	n.Language("de", "en")
+	n.Encoding("br", "gzip")
+	n.Charset("utf-8")
+}
+
+func BenchmarkNegotiatorNewPerCall(b *testing.B) {
+	header := setUpBrowserHeader()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		New(header).Type("application/json", "text/html")
+		New(header).Type("text/html", "text/plain")
+		New(header).Language("de", "en")
+		New(header).Encoding("br", "gzip")
+		New(header).Charset("utf-8")
+	}
+}
+
+func BenchmarkNegotiatorPerRequest(b *testing.B) {
+	header := setUpBrowserHeader()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		negotiateAll(New(header))
+	}
+}
+
+func BenchmarkNegotiatorReused(b *testing.B) {
+	n := New(setUpBrowserHeader())
+	negotiateAll(n)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		negotiateAll(n)
+	}
+}
@@ -0,0 +1,167 @@
+package negotiator
+
+import (
+	"strings"
+)
+
+// OfferSet is a precompiled, immutable set of offers. Negotiating against an
+// OfferSet gives the same result as passing its offers to Type, Language,
+// Encoding or Charset, but doesn't allocate. An OfferSet is safe for
+// concurrent use.
+type OfferSet struct {
+	offers []string
+	lower  []string
+	// types are the lowercase offers split into type, subtype and
+	// parameters, so that media ranges match them without splitting.
+	types []mediaType
+	// all lists the indexes of all the offers, in order.
+	all []int
+	// exact maps each lowercase offer without parameters to the indexes of
+	// the offers with that value, in order.
+	exact map[string][]int
+	// majors maps each major type of a media type offer to the indexes of
+	// the offers with that major type, in order.
+	majors map[string][]int
+	key    string
+}
+
+// NewOfferSet compiles offers into an OfferSet, indexed by exact value and
+// by major type.
+func NewOfferSet(offers ...string) *OfferSet {
+	set := &OfferSet{
+		offers: append([]string(nil), offers...),
+		lower:  make([]string, len(offers)),
+		types:  make([]mediaType, len(offers)),
+		all:    make([]int, len(offers)),
+		exact:  make(map[string][]int, len(offers)),
+		majors: make(map[string][]int),
+		key:    offersKey(offers),
+	}
+
+	for i, offer := range offers {
+		lower := strings.ToLower(offer)
+		mt := splitMediaType(lower)
+		set.lower[i], set.types[i], set.all[i] = lower, mt, i
+
+		val := mt.typ
+		if mt.subtype != "" || strings.IndexByte(lower, '/') != -1 {
+			val += "/" + mt.subtype
+			set.majors[mt.typ] = append(set.majors[mt.typ], i)
+		}
+		set.exact[val] = append(set.exact[val], i)
+	}
+
+	return set
+}
+
+// candidates returns the indexes of the offers which s can match: all the
+// offers for a wildcard, the offers with its major type for a "type/*" range
+// and the offers with its value otherwise.
+func (set *OfferSet) candidates(s spec, wildCard string) []int {
+	switch {
+	case s.val == wildCard:
+		return set.all
+	case wildCard == "*/*" && strings.HasSuffix(s.val, "/*"):
+		return set.majors[s.val[:len(s.val)-2]]
+	}
+
+	return set.exact[s.val]
+}
+
+// Offers returns the offers of set.
+func (set *OfferSet) Offers() []string {
+	return append([]string(nil), set.offers...)
+}
+
+// SelectType is like Type, but negotiates against set.
+func (n *Negotiator) SelectType(set *OfferSet) string {
+	return n.selectOfferSet(headerAccept, set)
+}
+
+// SelectLanguage is like Language, but negotiates against set.
+func (n *Negotiator) SelectLanguage(set *OfferSet) string {
+	return n.selectOfferSet(headerAcceptLanguage, set)
+}
+
+// SelectEncoding is like Encoding, but negotiates against set.
+func (n *Negotiator) SelectEncoding(set *OfferSet) string {
+	return n.selectOfferSet(headerAcceptEncoding, set)
+}
+
+// SelectCharset is like Charset, but negotiates against set.
+func (n *Negotiator) SelectCharset(set *OfferSet) string {
+	return n.selectOfferSet(headerAcceptCharset, set)
+}
+
+func (n *Negotiator) selectOfferSet(headerName string, set *OfferSet) string {
+	parser := n.parser(headerName)
+
+	if n.cache != nil && n.cache.decisions {
+		return n.decide(headerName, n.cache.decision(parser, headerName, n.fieldLines(headerName), set.key, func() string {
+			return parser.selectOfferSet(set, n.specs(headerName))
+		}))
+	}
+
+	return n.decide(headerName, parser.selectOfferSet(set, n.specs(headerName)))
+}
+
+// selectOfferSet is the equivalent of selectOffer for an OfferSet. An offer's
+// q is the q of a spec which matches it, and specs are sorted by q, so only
+// the candidates of the specs with at least the best q found so far are
+// matched.
+func (p headerParser) selectOfferSet(set *OfferSet, specs specs) string {
+	if len(specs) == 0 || specs[0].q == 0 {
+		return ""
+	}
+
+	if len(set.offers) == 0 {
+		return specs[0].val
+	}
+
+	bestIndex, bestQ, bestSpec := -1, qvalue(0), spec{}
+
+	for _, s := range specs {
+		if s.q == 0 || s.q < bestQ {
+			break
+		}
+
+		for _, i := range set.candidates(s, p.wildCard) {
+			q, m := p.matchOffer(set, i, specs)
+
+			if p.wins(i, q, m, bestIndex, bestQ, bestSpec) {
+				bestIndex, bestQ, bestSpec = i, q, m
+			}
+		}
+	}
+
+	if bestIndex == -1 {
+		return ""
+	}
+
+	return set.offers[bestIndex]
+}
+
+// matchOffer is the equivalent of match for the offer of set at index i.
+func (p headerParser) matchOffer(set *OfferSet, i int, specs specs) (qvalue, spec) {
+	if p.hasSlashVal {
+		return mediaRangeMatch(set.types[i], specs)
+	}
+
+	return p.match(set.lower[i], specs)
+}
+
+// wins reports whether the offer at index i with q matched by s wins over the
+// best offer so far, in the same way as selectIndex, which visits the offers
+// in order.
+func (p headerParser) wins(i int, q qvalue, s spec, bestIndex int, bestQ qvalue, bestSpec spec) bool {
+	switch {
+	case q == 0 || q < bestQ || i == bestIndex:
+		return false
+	case q > bestQ:
+		return true
+	case i < bestIndex:
+		return !p.tieBreak.prefers(bestSpec, s)
+	}
+
+	return p.tieBreak.prefers(s, bestSpec)
+}
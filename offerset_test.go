@@ -0,0 +1,132 @@
+package negotiator
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var offerSetCases = []struct {
+	header string
+	val    string
+	offers []string
+}{
+	{headerAccept, "", []string{"text/html", "application/json"}},
+	{headerAccept, "application/json;q=0.2, text/html", nil},
+	{headerAccept, "application/json;q=0.2, text/html", []string{"text/plain"}},
+	{headerAccept, "application/json;q=0", []string{"application/json"}},
+	{headerAccept, "text/*", []string{"text/*"}},
+	{headerAccept, "text/*", []string{"application/json", "TEXT/HTML", "text/plain"}},
+	{headerAccept, "*/*, application/json;q=0.2", []string{"text/html", "application/json", "text/plain"}},
+	{headerAccept, "*/*, application/json;q=0.2", []string{"application/json"}},
+	{headerAccept, "*/*;q=0.1, application/json;q=0.2", []string{"*/*", "application/json"}},
+	{headerAccept, "text/html, application/*;q=0.9, image/jpeg;q=0.8", []string{"image/jpeg", "application/json", "text/plain"}},
+	{headerAccept, "text/*;q=0.5, text/html;q=0.1, */*;q=0.3", []string{"text/html", "application/json", "text/plain"}},
+	{headerAccept, "image/*, text/*;q=0.5", []string{"text/plain", "IMAGE/png", "image/webp"}},
+	{headerAccept, "text/plain;format=flowed, text/*;q=0.5", []string{"text/plain", "text/plain;format=flowed"}},
+	{headerAcceptLanguage, "*, ko;q=0.5", []string{"ko", "en", "zh"}},
+	{headerAcceptLanguage, "en;q=0.8, es, pt", []string{"en", "pt", "es"}},
+	{headerAcceptLanguage, "*", []string{"*"}},
+	{headerAcceptEncoding, "gzip, compress;q=0.2, identity;q=0.5", []string{"compress", "identity"}},
+	{headerAcceptCharset, "UTF-8;q=0.6, ISO-8859-1;q=0.8, UTF-8;q=0.9", []string{"UTF-8", "ISO-8859-1", "ASCII"}},
+}
+
+type OfferSetSuite struct {
+	suite.Suite
+}
+
+func (s OfferSetSuite) TestSameAsVariadic() {
+	for _, c := range offerSetCases {
+		n := setUpNegotiator(c.header, c.val)
+		set := NewOfferSet(c.offers...)
+
+		var want, got string
+
+		switch c.header {
+		case headerAccept:
+			want, got = n.Type(c.offers...), n.SelectType(set)
+		case headerAcceptLanguage:
+			want, got = n.Language(c.offers...), n.SelectLanguage(set)
+		case headerAcceptEncoding:
+			want, got = n.Encoding(c.offers...), n.SelectEncoding(set)
+		case headerAcceptCharset:
+			want, got = n.Charset(c.offers...), n.SelectCharset(set)
+		}
+
+		s.Equal(want, got, "%s: %q %q", c.header, c.val, c.offers)
+	}
+}
+
+func (s OfferSetSuite) TestSameAsVariadicTieBreaks() {
+	header := make(http.Header)
+	header.Set(headerAccept, "text/*, application/json, */*")
+	offers := []string{"image/png", "text/plain", "application/json", "text/html"}
+
+	for _, t := range []TieBreak{TieBreakServer, TieBreakClient, TieBreakSpecificity} {
+		n := New(header, WithTieBreak(t))
+		s.Equal(n.Type(offers...), n.SelectType(NewOfferSet(offers...)), "tie break %d", t)
+	}
+}
+
+func (s OfferSetSuite) TestIndexes() {
+	set := NewOfferSet("text/html", "application/json", "Text/Plain; format=flowed", "en", "text/html")
+
+	s.Equal(mediaType{typ: "text", subtype: "plain", params: " format=flowed"}, set.types[2])
+	s.Equal([]int{0, 2, 4}, set.candidates(spec{val: "text/*"}, "*/*"))
+	s.Equal([]int(nil), set.candidates(spec{val: "image/*"}, "*/*"))
+	s.Equal([]int{0, 1, 2, 3, 4}, set.candidates(spec{val: "*/*"}, "*/*"))
+	s.Equal([]int{0, 4}, set.candidates(spec{val: "text/html"}, "*/*"))
+	s.Equal([]int{2}, set.candidates(spec{val: "text/plain", params: "format=flowed"}, "*/*"))
+	s.Equal([]int{3}, set.candidates(spec{val: "en"}, "*"))
+	s.Equal([]int{0, 1, 2, 3, 4}, set.candidates(spec{val: "*"}, "*"))
+}
+
+func (s OfferSetSuite) TestOffersAreCopied() {
+	offers := []string{"text/html", "application/json"}
+	set := NewOfferSet(offers...)
+	offers[0] = "text/plain"
+
+	s.Equal([]string{"text/html", "application/json"}, set.Offers())
+}
+
+func (s OfferSetSuite) TestCachedDecisions() {
+	cache := NewCache(8, true)
+	set := NewOfferSet("application/json", "text/html")
+
+	header := make(http.Header)
+	header.Set(headerAccept, "text/html, application/*;q=0.9")
+
+	s.Equal("text/html", New(header, WithCache(cache)).SelectType(set))
+	s.Equal("text/html", New(header, WithCache(cache)).SelectType(set))
+	s.Equal(uint64(1), cache.Stats().Hits)
+}
+
+func (s OfferSetSuite) TestZeroAllocations() {
+	n := New(setUpBrowserHeader())
+	types := NewOfferSet("application/json", "text/html", "image/webp")
+	languages := NewOfferSet("de", "en")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		n.SelectType(types)
+		n.SelectLanguage(languages)
+	})
+
+	s.Equal(0.0, allocs)
+}
+
+func TestOfferSet(t *testing.T) {
+	suite.Run(t, new(OfferSetSuite))
+}
+
+func BenchmarkNegotiatorOfferSet(b *testing.B) {
+	n := New(setUpBrowserHeader())
+	types := NewOfferSet("application/json", "text/html", "image/webp")
+	n.SelectType(types)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		n.SelectType(types)
+	}
+}
@@ -0,0 +1,177 @@
+package negotiator
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Source represents where a negotiation decision came from.
+type Source string
+
+const (
+	// SourceAccept means the decision came from the HTTP Accept-* header.
+	SourceAccept Source = "accept"
+	// SourceExtension means the decision came from the extension of the
+	// URL path, e.g. "/users.json".
+	SourceExtension Source = "extension"
+	// SourceQuery means the decision came from a URL query parameter, e.g.
+	// "/users?format=json".
+	SourceQuery Source = "query"
+)
+
+// FormatOverride configures how the URL of a request can override the HTTP
+// Accept header.
+type FormatOverride struct {
+	// Extensions enables overrides by the extension of the URL path.
+	Extensions bool
+	// QueryParam is the name of the query parameter which overrides the
+	// Accept header, e.g. "format". Empty disables query overrides.
+	QueryParam string
+	// Types maps formats, i.e. extensions without the leading dot or query
+	// values, to media types. Formats missing from Types are looked up with
+	// mime.TypeByExtension.
+	Types map[string]string
+}
+
+// TypeMatch represents the result of FormatOverride.Type.
+type TypeMatch struct {
+	// Type is the selected media type, or empty if nothing accepted.
+	Type string
+	// Source is where Type came from.
+	Source Source
+	// Format is the requested format if Source isn't SourceAccept.
+	Format string
+	// Location is the request URI including the format if Source isn't
+	// SourceAccept.
+	Location string
+}
+
+// SetHeaders sets the Vary or Content-Location header of the response in h
+// according to m.
+func (m TypeMatch) SetHeaders(h http.Header) {
+	if m.Source == SourceAccept {
+		h.Add(headerVary, headerAccept)
+		return
+	}
+
+	h.Set(headerContentLocation, m.Location)
+}
+
+type override struct {
+	source   Source
+	format   string
+	typ      string
+	location string
+}
+
+func (o FormatOverride) typeByFormat(format string) string {
+	if typ, ok := o.Types[format]; ok {
+		return typ
+	}
+
+	typ := mime.TypeByExtension("." + format)
+	if i := strings.Index(typ, ";"); i != -1 {
+		typ = typ[:i]
+	}
+
+	return strings.TrimSpace(typ)
+}
+
+func (o FormatOverride) extension(urlPath string) (format, typ string) {
+	ext := path.Ext(urlPath)
+	if len(ext) < 2 {
+		return
+	}
+
+	format = strings.ToLower(ext[1:])
+	if typ = o.typeByFormat(format); typ == "" {
+		format = ""
+	}
+
+	return
+}
+
+func (o FormatOverride) override(r *http.Request) (ov override, ok bool) {
+	if o.QueryParam != "" {
+		if format := strings.ToLower(r.URL.Query().Get(o.QueryParam)); format != "" {
+			return override{SourceQuery, format, o.typeByFormat(format), r.URL.RequestURI()}, true
+		}
+	}
+
+	if ov, ok = r.Context().Value(overrideKey).(override); ok {
+		return
+	}
+
+	if o.Extensions {
+		if format, typ := o.extension(r.URL.Path); format != "" {
+			return override{SourceExtension, format, typ, r.URL.RequestURI()}, true
+		}
+	}
+
+	return
+}
+
+// Type returns the most preferred content type of r. A format requested by
+// the query parameter or the extension of the URL path takes precedence over
+// the HTTP Accept header. If the requested format is not one of offers, then
+// the returned Type is empty.
+func (o FormatOverride) Type(r *http.Request, offers ...string) (m TypeMatch) {
+	ov, ok := o.override(r)
+	if !ok {
+		return TypeMatch{Type: FromRequest(r).Type(offers...), Source: SourceAccept}
+	}
+
+	m = TypeMatch{Source: ov.source, Format: ov.format, Location: ov.location}
+
+	if len(offers) == 0 {
+		m.Type = ov.typ
+		return
+	}
+
+	for _, offer := range offers {
+		if ov.typ != "" && strings.EqualFold(offer, ov.typ) {
+			m.Type = offer
+			break
+		}
+	}
+
+	return
+}
+
+// Handler returns a handler which strips a known extension from the URL path
+// of each request before calling h, so that routing doesn't see it. The
+// stripped extension is remembered and still takes precedence over the Accept
+// header in Type.
+func (o FormatOverride) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !o.Extensions {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		format, typ := o.extension(r.URL.Path)
+		if format == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		ov := override{SourceExtension, format, typ, r.URL.RequestURI()}
+		r = r.WithContext(context.WithValue(r.Context(), overrideKey, ov))
+
+		u := *r.URL
+		ext := path.Ext(u.Path)
+		u.Path = strings.TrimSuffix(u.Path, ext)
+		if strings.HasSuffix(u.RawPath, ext) {
+			u.RawPath = strings.TrimSuffix(u.RawPath, ext)
+		} else {
+			u.RawPath = ""
+		}
+		r.URL = &u
+		setRequest(r)
+
+		h.ServeHTTP(w, r)
+	})
+}
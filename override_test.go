@@ -0,0 +1,110 @@
+package negotiator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var formatOverride = FormatOverride{
+	Extensions: true,
+	QueryParam: "format",
+	Types:      map[string]string{"csv": "text/csv"},
+}
+
+func setUpOverrideRequest(target, accept string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set(headerAccept, accept)
+
+	return req
+}
+
+type OverrideSuite struct {
+	suite.Suite
+}
+
+func (s OverrideSuite) TestAccept() {
+	req := setUpOverrideRequest("/users", "application/json")
+	m := formatOverride.Type(req, "text/html", "application/json")
+
+	s.Equal(TypeMatch{Type: "application/json", Source: SourceAccept}, m)
+}
+
+func (s OverrideSuite) TestExtension() {
+	req := setUpOverrideRequest("/users.JSON", "text/html")
+	m := formatOverride.Type(req, "text/html", "application/json")
+
+	s.Equal(TypeMatch{Type: "application/json", Source: SourceExtension, Format: "json", Location: "/users.JSON"}, m)
+}
+
+func (s OverrideSuite) TestUnknownExtension() {
+	req := setUpOverrideRequest("/users.unknownext", "text/html")
+	m := formatOverride.Type(req, "text/html", "application/json")
+
+	s.Equal(SourceAccept, m.Source)
+	s.Equal("text/html", m.Type)
+}
+
+func (s OverrideSuite) TestQuery() {
+	req := setUpOverrideRequest("/users.json?format=csv", "text/html")
+	m := formatOverride.Type(req, "text/csv", "application/json")
+
+	s.Equal(TypeMatch{Type: "text/csv", Source: SourceQuery, Format: "csv", Location: "/users.json?format=csv"}, m)
+}
+
+func (s OverrideSuite) TestOverrideNotOffered() {
+	req := setUpOverrideRequest("/users?format=csv", "*/*")
+	m := formatOverride.Type(req, "application/json")
+
+	s.Equal("", m.Type)
+	s.Equal(SourceQuery, m.Source)
+}
+
+func (s OverrideSuite) TestSetHeaders() {
+	h := make(http.Header)
+	TypeMatch{Type: "application/json", Source: SourceAccept}.SetHeaders(h)
+
+	s.Equal(headerAccept, h.Get(headerVary))
+	s.Equal("", h.Get(headerContentLocation))
+
+	h = make(http.Header)
+	TypeMatch{Type: "text/csv", Source: SourceQuery, Location: "/users?format=csv"}.SetHeaders(h)
+
+	s.Equal("", h.Get(headerVary))
+	s.Equal("/users?format=csv", h.Get(headerContentLocation))
+}
+
+func (s OverrideSuite) TestHandler() {
+	var path string
+	var m TypeMatch
+
+	h := formatOverride.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		m = formatOverride.Type(r, "text/html", "application/json")
+	}))
+
+	req := setUpOverrideRequest("/users/1.json", "text/html")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.Equal("/users/1", path)
+	s.Equal("/users/1.json", req.URL.Path)
+	s.Equal(TypeMatch{Type: "application/json", Source: SourceExtension, Format: "json", Location: "/users/1.json"}, m)
+}
+
+func (s OverrideSuite) TestHandlerWithoutExtension() {
+	var path string
+
+	h := formatOverride.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), setUpOverrideRequest("/v1.2/users", "text/html"))
+
+	s.Equal("/v1.2/users", path)
+}
+
+func TestOverride(t *testing.T) {
+	suite.Run(t, new(OverrideSuite))
+}
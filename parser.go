@@ -2,94 +2,251 @@ package negotiator
 
 import (
 	"net/http"
-	"sort"
-	"strconv"
 	"strings"
 )
 
 type headerParser struct {
 	header      http.Header
 	hasSlashVal bool
-	defaultQ    float64
+	defaultQ    qvalue
 	wildCard    string
+	limits      Limits
+	strict      bool
+	tieBreak    TieBreak
+	// caseSensitive makes values compare exactly, as the URIs of
+	// Accept-Profile do.
+	caseSensitive bool
+	// keepExcluded keeps values with q=0, which exclude them from matching
+	// the wildcard, as language ranges do.
+	keepExcluded bool
 }
 
 func newHeaderParser(header http.Header, hasSlashVal bool) *headerParser {
-	hp := &headerParser{header: header, hasSlashVal: hasSlashVal, defaultQ: 1.0}
+	hp := &headerParser{header: header, hasSlashVal: hasSlashVal}
+	hp.init()
 
-	if hp.hasSlashVal {
-		hp.wildCard = "*/*"
+	return hp
+}
+
+func (p *headerParser) init() {
+	p.defaultQ = maxQ
+
+	if p.hasSlashVal {
+		p.wildCard = "*/*"
 	} else {
-		hp.wildCard = "*"
+		p.wildCard = "*"
 	}
+}
 
-	return hp
+// parse parses the header in a single pass over its raw value. Values which
+// are already lowercase and free of inner spaces are not copied.
+func (p headerParser) parse(headerName string) specs {
+	ss, _ := p.parseHeader(headerName)
+	return ss
 }
 
-func (p headerParser) parse(headerName string) (specs specs) {
-	headerVal := formatHeaderVal(p.header.Get(headerName))
+// parseHeader is like parse, but also reports if the header exceeds the
+// limits of p and p.limits.Action is LimitReject, or if p is strict and the
+// header is malformed.
+func (p headerParser) parseHeader(headerName string) (specs, error) {
+	switch {
+	case isWantDigest(headerName):
+		return p.parseWantDigest(headerName)
+	case headerName == headerAcceptProfile:
+		return p.parseProfiles(headerName)
+	}
 
-	if headerVal == "" {
-		specs = []spec{spec{val: p.wildCard, q: p.defaultQ}}
-		return
+	headerVal := p.header.Get(headerName)
+
+	if strings.Trim(headerVal, " ") == "" {
+		return p.absent(), nil
 	}
 
-	for _, accept := range strings.Split(headerVal, ",") {
-		pair := strings.Split(strings.TrimSpace(accept), ";")
+	if max := p.limits.MaxBytes; max > 0 && len(headerVal) > max {
+		if p.limits.Action != LimitTruncate {
+			return p.exceeded(headerName, "bytes", max)
+		}
 
-		if len(pair) < 1 || len(pair) > 2 {
-			if p.hasSlashVal {
-				if strings.Index(pair[0], "/") == -1 {
-					continue
-				}
-			} else {
-				continue
-			}
+		headerVal = headerVal[:max]
+		if i := strings.LastIndexByte(headerVal, ','); i != -1 {
+			headerVal = headerVal[:i]
+		} else {
+			headerVal = ""
+		}
+	}
+
+	// The returned specs are owned by the caller and may be memoized, so
+	// they are allocated once with room for every element.
+	size := strings.Count(headerVal, ",") + 1
+	if max := p.limits.MaxElements; max > 0 && size > max {
+		size = max
+	}
+	ss := make(specs, 0, size)
+	elements := 0
+
+	for index, offset, rest := 0, 0, headerVal; len(rest) > 0; index++ {
+		var element string
+
+		if i := strings.IndexByte(rest, ','); i != -1 {
+			element, rest = rest[:i], rest[i+1:]
+		} else {
+			element, rest = rest, ""
 		}
 
-		spec := spec{val: pair[0], q: p.defaultQ}
+		start := offset
+		offset += len(element) + 1
 
-		if len(pair) == 2 {
-			var i int
+		if p.strict {
+			if reason, at := p.checkElement(element); reason != "" {
+				return nil, &ParseError{Header: headerName, Index: index, Offset: start + at, Reason: reason}
+			}
+		}
 
-			if strings.HasPrefix(pair[1], "q=") {
-				i = 2
-			} else if strings.HasPrefix(pair[1], "level=") {
-				i = 6
-			} else {
-				continue
+		if strings.TrimSpace(element) == "" {
+			continue
+		}
+
+		if max := p.limits.MaxElements; max > 0 && elements == max {
+			if p.limits.Action != LimitTruncate {
+				return p.exceeded(headerName, "elements", max)
 			}
 
-			if q, err := strconv.ParseFloat(pair[1][i:], 64); err == nil && q != 0.0 {
-				if q > p.defaultQ {
-					q = p.defaultQ
-				}
+			break
+		}
+		elements++
 
-				spec.q = q
-			} else {
-				continue
+		if max := p.limits.MaxParams; max > 0 && strings.Count(element, ";") > max {
+			if p.limits.Action != LimitTruncate {
+				return p.exceeded(headerName, "params", max)
 			}
+
+			continue
 		}
 
-		specs = append(specs, spec)
+		if spec, ok := p.parseElement(element); ok {
+			spec.index = len(ss)
+			ss = append(ss, spec)
+		}
 	}
 
-	sort.Sort(specs)
+	if len(ss) == 0 {
+		return nil, nil
+	}
 
-	return
+	ss.sort()
+
+	return ss, nil
 }
 
-func (p headerParser) selectOffer(offers []string, specs specs) (bestOffer string) {
-	bestQ := 0.0
+// absent returns the specs of an absent header, which accepts anything.
+func (p headerParser) absent() specs {
+	return specs{spec{val: p.wildCard, q: p.defaultQ}}
+}
+
+func (p headerParser) exceeded(headerName, limit string, max int) (specs, error) {
+	if p.limits.Action == LimitIgnore {
+		return p.absent(), nil
+	}
+
+	return nil, &LimitError{Header: headerName, Limit: limit, Max: max}
+}
+
+// parseElement parses a single element of a header, such as
+// "text/html;q=0.8".
+func (p headerParser) parseElement(element string) (s spec, ok bool) {
+	val, params := element, ""
+	hasParams := false
+
+	if i := strings.IndexByte(element, ';'); i != -1 {
+		val, params, hasParams = element[:i], element[i+1:], true
+	}
+
+	if s.val = formatToken(val); s.val == "" {
+		return
+	}
+	s.q = p.defaultQ
+
+	if !hasParams {
+		return s, true
+	}
 
-	var bestWild, totalWild int
 	if p.hasSlashVal {
-		bestWild, totalWild = 3, 3
+		return p.parseMediaRangeParams(s, params)
+	}
+
+	if strings.IndexByte(params, ';') != -1 {
+		return
+	}
+
+	param := formatToken(params)
+
+	var i int
+	if strings.HasPrefix(param, "q=") {
+		i = 2
+	} else if strings.HasPrefix(param, "level=") {
+		i = 6
 	} else {
-		bestWild, totalWild = 2, 2
+		return
+	}
+
+	q, valid := parseLenientQValue(param[i:])
+	if !valid || (q == 0 && !p.keepExcluded) {
+		return
+	}
+	if q > p.defaultQ {
+		q = p.defaultQ
+	}
+	s.q = q
+
+	return s, true
+}
+
+// checkElement returns why element is malformed and the offset of the
+// malformed part within element, or an empty reason if it's well-formed.
+func (p headerParser) checkElement(element string) (reason string, at int) {
+	start := 0
+
+	for i, part := range strings.Split(element, ";") {
+		token := strings.TrimSpace(part)
+		at = start + strings.Index(part, token)
+		start += len(part) + 1
+
+		if i == 0 {
+			if token == "" {
+				return "empty element", at
+			}
+
+			if slash := strings.IndexByte(token, '/'); p.hasSlashVal && (slash <= 0 || slash == len(token)-1) {
+				return "missing subtype", at
+			}
+
+			continue
+		}
+
+		eq := strings.IndexByte(token, '=')
+		if eq <= 0 || eq == len(token)-1 {
+			return "invalid parameter", at
+		}
+
+		if strings.ToLower(strings.TrimSpace(token[:eq])) != "q" {
+			continue
+		}
+
+		val := strings.TrimSpace(token[eq+1:])
+		if _, valid := parseQValue(val); !valid {
+			if q, lenient := parseLenientQValue(val); lenient && q == maxQ {
+				return "q out of range", at
+			}
+
+			return "invalid q", at
+		}
 	}
 
-	if len(specs) == 0 {
+	return "", 0
+}
+
+func (p headerParser) selectOffer(offers []string, specs specs) (bestOffer string) {
+	if len(specs) == 0 || specs[0].q == 0 {
 		return
 	}
 
@@ -98,39 +255,109 @@ func (p headerParser) selectOffer(offers []string, specs specs) (bestOffer strin
 		return
 	}
 
-	for _, offer := range offers {
-		lowerCaseOffer := strings.ToLower(offer)
+	if i := p.selectIndex(offers, specs); i != -1 {
+		bestOffer = offers[i]
+	}
+
+	return
+}
+
+// selectIndex returns the index of the most preferred offer, breaking ties
+// according to p.tieBreak, or -1 if nothing accepted.
+func (p headerParser) selectIndex(offers []string, specs specs) int {
+	bestIndex, bestQ, bestSpec := -1, qvalue(0), spec{}
+
+	for i, offer := range offers {
+		q, s := p.match(offer, specs)
+
+		if q > bestQ || (q == bestQ && q > 0 && p.tieBreak.prefers(s, bestSpec)) {
+			bestIndex, bestQ, bestSpec = i, q, s
+		}
+	}
+
+	return bestIndex
+}
 
-		for _, spec := range specs {
-			switch {
-			case spec.q <= bestQ:
+// quality returns the q of offer, or 0 if offer is not acceptable.
+func (p headerParser) quality(offer string, specs specs) qvalue {
+	q, _ := p.match(offer, specs)
+	return q
+}
+
+// match returns the q of offer and the spec which decided it. For media
+// types it's the most specific matching media range, otherwise the matching
+// spec with the highest q. It takes time linear in the number of specs.
+func (p headerParser) match(offer string, specs specs) (q qvalue, m spec) {
+	if p.hasSlashVal {
+		return mediaRangeMatch(splitMediaType(offer), specs)
+	}
+
+	// listed reports whether offer itself is in specs, which excludes it
+	// from matching the wildcard. It's computed at most once.
+	listed, checked := false, false
+
+	for _, spec := range specs {
+		if spec.q <= q {
+			continue
+		}
+
+		if spec.val == p.wildCard {
+			if !checked {
+				listed, checked = p.listed(offer, specs), true
+			}
+
+			if !listed {
+				q, m = spec.q, spec
 				continue
-			case spec.val == p.wildCard && !specs.hasVal(lowerCaseOffer):
-				if spec.q > bestQ || bestWild > totalWild-1 {
-					bestOffer = offer
-
-					bestQ, bestWild = spec.q, totalWild-1
-				}
-			case p.hasSlashVal && strings.HasSuffix(spec.val, "/*"):
-				if strings.HasPrefix(lowerCaseOffer, spec.val[:len(spec.val)-1]) &&
-					(spec.q > bestQ || bestWild > totalWild-2) {
-					bestOffer = offer
-
-					bestQ, bestWild = spec.q, totalWild-2
-				}
-			case spec.val == lowerCaseOffer:
-				if spec.q > bestQ || bestWild > 0 {
-					bestOffer = offer
-
-					bestQ, bestWild = spec.q, 0
-				}
 			}
 		}
+
+		if p.equal(spec.val, offer) {
+			q, m = spec.q, spec
+		}
 	}
 
 	return
 }
 
+// listed reports whether offer is one of the values of specs.
+func (p headerParser) listed(offer string, specs specs) bool {
+	if !p.caseSensitive {
+		return specs.hasVal(offer)
+	}
+
+	for _, spec := range specs {
+		if spec.val == offer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equal reports whether the value val matches offer.
+func (p headerParser) equal(val, offer string) bool {
+	if p.caseSensitive {
+		return val == offer
+	}
+
+	return strings.EqualFold(val, offer)
+}
+
 func formatHeaderVal(val string) string {
 	return strings.ToLower(strings.Replace(val, " ", "", -1))
 }
+
+// formatToken is like formatHeaderVal, but returns val itself rather than a
+// copy if it's already formatted apart from leading and trailing spaces.
+func formatToken(val string) string {
+	val = strings.TrimSpace(val)
+
+	for i := 0; i < len(val); i++ {
+		if c := val[i]; c == ' ' || ('A' <= c && c <= 'Z') {
+			return formatHeaderVal(val)
+		}
+	}
+
+	return val
+}
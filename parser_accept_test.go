@@ -59,7 +59,10 @@ func (s *ParseAcceptTestSuite) TestOneTypeWithQZero() {
 	s.header.Set(headerAccept, "application/json;q=0")
 	specs := s.parser.parse(headerAccept)
 
-	assert.Equal(0, len(specs))
+	// Media ranges with q=0 are kept to exclude less specific ranges.
+	assert.Equal(1, len(specs))
+
+	equalSpec(assert, specs[0], "application/json", 0)
 }
 
 func (s *ParseAcceptTestSuite) TestSortByQ() {
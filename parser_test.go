@@ -0,0 +1,167 @@
+package negotiator
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// browserHeaders is a corpus of header values sent by real user agents.
+var browserHeaders = []struct {
+	name string
+	val  string
+}{
+	// Chrome
+	{headerAccept, "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"},
+	{headerAcceptLanguage, "en-US,en;q=0.9"},
+	{headerAcceptEncoding, "gzip, deflate, br, zstd"},
+	// Firefox
+	{headerAccept, "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8"},
+	{headerAcceptLanguage, "de,en-US;q=0.7,en;q=0.3"},
+	{headerAcceptEncoding, "gzip, deflate, br"},
+	// Safari
+	{headerAccept, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"},
+	{headerAcceptLanguage, "en-GB,en;q=0.9"},
+	// Images and XHR
+	{headerAccept, "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8"},
+	{headerAccept, "application/json, text/plain, */*"},
+	// curl and older browsers
+	{headerAccept, "*/*"},
+	{headerAcceptCharset, "ISO-8859-1,utf-8;q=0.7,*;q=0.7"},
+	{headerAcceptLanguage, "zh-CN, zh;q=0.8, zh-TW;q=0.7, zh-HK;q=0.5, en-US;q=0.3, en;q=0.2"},
+}
+
+// legacyParse is the previous implementation of headerParser.parse, kept to
+// check and benchmark the current one against. It only differs in sorting
+// stably, rounding q to a qvalue and recording the index of each spec.
+func legacyParse(p headerParser, headerName string) (specs specs) {
+	headerVal := formatHeaderVal(p.header.Get(headerName))
+
+	if headerVal == "" {
+		specs = []spec{spec{val: p.wildCard, q: p.defaultQ}}
+		return
+	}
+
+	for _, accept := range strings.Split(headerVal, ",") {
+		pair := strings.Split(strings.TrimSpace(accept), ";")
+
+		if len(pair) < 1 || len(pair) > 2 {
+			if p.hasSlashVal {
+				if strings.Index(pair[0], "/") == -1 {
+					continue
+				}
+			} else {
+				continue
+			}
+		}
+
+		spec := spec{val: pair[0], q: p.defaultQ}
+
+		if len(pair) == 2 {
+			var i int
+
+			if strings.HasPrefix(pair[1], "q=") {
+				i = 2
+			} else if strings.HasPrefix(pair[1], "level=") {
+				i = 6
+			} else {
+				continue
+			}
+
+			if q, err := strconv.ParseFloat(pair[1][i:], 64); err == nil && q != 0.0 {
+				if q > p.defaultQ.float() {
+					q = p.defaultQ.float()
+				}
+
+				spec.q = qvalue(q*float64(maxQ) + 0.5)
+			} else {
+				continue
+			}
+		}
+
+		spec.index = len(specs)
+		specs = append(specs, spec)
+	}
+
+	sort.Stable(specs)
+
+	return
+}
+
+func TestParseSameAsLegacy(t *testing.T) {
+	for _, h := range browserHeaders {
+		if strings.Contains(h.val, ";v=") {
+			// The legacy parser ignored q of media ranges with parameters.
+			continue
+		}
+
+		header := make(http.Header)
+		header.Set(h.name, h.val)
+		parser := newHeaderParser(header, h.name == headerAccept)
+
+		assert.Equal(t, legacyParse(*parser, h.name), parser.parse(h.name), h.val)
+	}
+}
+
+func TestParseFormatsValues(t *testing.T) {
+	header := make(http.Header)
+	header.Set(headerAccept, " Text/HTML , application/ json ; q = 0.5")
+	specs := newHeaderParser(header, true).parse(headerAccept)
+
+	assert.Equal(t, 2, len(specs))
+	equalSpec(assert.New(t), specs[0], "text/html", 1.0)
+	equalSpec(assert.New(t), specs[1], "application/json", 0.5)
+}
+
+func TestParseSkipsEmptyElements(t *testing.T) {
+	header := make(http.Header)
+	header.Set(headerAcceptEncoding, "gzip,, ,br")
+	specs := newHeaderParser(header, false).parse(headerAcceptEncoding)
+
+	assert.Equal(t, 2, len(specs))
+}
+
+func TestParseAllocatesResultOnly(t *testing.T) {
+	header := make(http.Header)
+	header.Set(headerAccept, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	p := newHeaderParser(header, true)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		p.parse(headerAccept)
+	})
+
+	assert.Equal(t, 1.0, allocs)
+}
+
+func benchmarkParse(b *testing.B, parse func(p headerParser, headerName string) specs) {
+	parsers := make([]*headerParser, len(browserHeaders))
+
+	for i, h := range browserHeaders {
+		header := make(http.Header)
+		header.Set(h.name, h.val)
+		parsers[i] = newHeaderParser(header, h.name == headerAccept)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, h := range browserHeaders {
+			parse(*parsers[j], h.name)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	benchmarkParse(b, func(p headerParser, headerName string) specs {
+		return p.parse(headerName)
+	})
+}
+
+func BenchmarkParseLegacy(b *testing.B) {
+	benchmarkParse(b, legacyParse)
+}
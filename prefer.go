@@ -0,0 +1,261 @@
+package negotiator
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerPrefer            = "Prefer"
+	headerPreferenceApplied = "Preference-Applied"
+)
+
+// Preference represents a preference of the HTTP Prefer header, such as
+// "return=minimal" or "respond-async".
+type Preference struct {
+	// Name is the lowercase name of the preference.
+	Name string
+	// Value is the value of the preference without quotes, or empty.
+	Value string
+	// Params are the parameters of the preference, keyed by lowercase name.
+	Params map[string]string
+}
+
+// Preferences represents the preferences of a request, as defined in
+// RFC 7240. It records which of them the server applied, and is safe for
+// concurrent use.
+type Preferences struct {
+	prefs []Preference
+
+	mu      sync.Mutex
+	applied []Preference
+}
+
+// ParsePrefer parses the HTTP Prefer headers of header. Preference names are
+// case-insensitive and only the first occurrence of a preference is used.
+func ParsePrefer(header http.Header) *Preferences {
+	p := &Preferences{}
+
+	for _, val := range header[headerPrefer] {
+		for _, element := range splitQuoted(val, ',') {
+			params := splitQuoted(element, ';')
+
+			name, value := splitParam(params[0])
+			if name == "" || p.has(name) {
+				continue
+			}
+
+			pref := Preference{Name: name, Value: value}
+
+			for _, param := range params[1:] {
+				if name, value := splitParam(param); name != "" {
+					if pref.Params == nil {
+						pref.Params = make(map[string]string)
+					}
+					pref.Params[name] = value
+				}
+			}
+
+			p.prefs = append(p.prefs, pref)
+		}
+	}
+
+	return p
+}
+
+func (p *Preferences) has(name string) bool {
+	_, ok := p.Get(name)
+	return ok
+}
+
+// Get returns the preference with the given name.
+func (p *Preferences) Get(name string) (pref Preference, ok bool) {
+	for _, pref := range p.prefs {
+		if strings.EqualFold(pref.Name, name) {
+			return pref, true
+		}
+	}
+
+	return
+}
+
+// All returns the preferences in the order of the header.
+func (p *Preferences) All() []Preference {
+	return append([]Preference(nil), p.prefs...)
+}
+
+// Return returns the value of the "return" preference: "minimal",
+// "representation" or empty.
+func (p *Preferences) Return() string {
+	pref, _ := p.Get("return")
+	return strings.ToLower(pref.Value)
+}
+
+// RespondAsync reports whether the "respond-async" preference is present.
+func (p *Preferences) RespondAsync() bool {
+	return p.has("respond-async")
+}
+
+// Wait returns the duration of the "wait" preference.
+func (p *Preferences) Wait() (d time.Duration, ok bool) {
+	pref, ok := p.Get("wait")
+	if !ok {
+		return
+	}
+
+	seconds, err := strconv.ParseUint(pref.Value, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// Handling returns the value of the "handling" preference: "strict",
+// "lenient" or empty.
+func (p *Preferences) Handling() string {
+	pref, _ := p.Get("handling")
+	return strings.ToLower(pref.Value)
+}
+
+// Apply marks the preference with the given name as applied, so that it is
+// listed in the HTTP Preference-Applied header. It returns false if the
+// request has no such preference.
+func (p *Preferences) Apply(name string) bool {
+	pref, ok := p.Get(name)
+	if !ok {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, applied := range p.applied {
+		if applied.Name == pref.Name {
+			return true
+		}
+	}
+
+	p.applied = append(p.applied, Preference{Name: pref.Name, Value: pref.Value})
+
+	return true
+}
+
+// Applied returns the value of the HTTP Preference-Applied header, or empty
+// if no preference was applied.
+func (p *Preferences) Applied() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	applied := make([]string, len(p.applied))
+	for i, pref := range p.applied {
+		applied[i] = pref.Name
+		if pref.Value != "" {
+			applied[i] += "=" + quoteWord(pref.Value)
+		}
+	}
+
+	return strings.Join(applied, ", ")
+}
+
+// Preferences returns the parsed HTTP Prefer header of the request.
+func (n *Negotiator) Preferences() *Preferences {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.prefs == nil {
+		n.prefs = ParsePrefer(n.header)
+	}
+
+	return n.prefs
+}
+
+// PreferHandler returns a handler which attaches a Negotiator to the request
+// context, like Handler. When the response header is written, it adds
+// "Vary: Prefer" and the preferences applied through
+// FromRequest(r).Preferences().Apply as the HTTP Preference-Applied header.
+func PreferHandler(h http.Handler, opts ...Option) http.Handler {
+	return Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefs := FromRequest(r).Preferences()
+
+		serveWithHeaders(h, w, r, func(h http.Header) {
+			h.Add(headerVary, headerPrefer)
+			if applied := prefs.Applied(); applied != "" {
+				h.Set(headerPreferenceApplied, applied)
+			}
+		})
+	}), opts...)
+}
+
+// splitQuoted splits s around sep, except inside quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted, escaped := false, false
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// isToken reports whether s is a token as defined in RFC 9110.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isTChar(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// isTChar reports whether c is a token character as defined in RFC 9110.
+func isTChar(c byte) bool {
+	return isAlpha(c) || isDigit(c) || strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1
+}
+
+// quoteWord returns s as a token if possible, otherwise as a quoted string.
+func quoteWord(s string) string {
+	if isToken(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
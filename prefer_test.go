@@ -0,0 +1,147 @@
+package negotiator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func setUpPreferences(vals ...string) *Preferences {
+	header := make(http.Header)
+	for _, val := range vals {
+		header.Add(headerPrefer, val)
+	}
+
+	return ParsePrefer(header)
+}
+
+type PreferSuite struct {
+	suite.Suite
+}
+
+func (s PreferSuite) TestEmpty() {
+	p := setUpPreferences()
+
+	s.Empty(p.All())
+	s.Equal("", p.Return())
+	s.False(p.RespondAsync())
+	s.False(p.Apply("return"))
+	s.Equal("", p.Applied())
+}
+
+func (s PreferSuite) TestParse() {
+	p := setUpPreferences(`Return=minimal; foo="a;b", respond-async`, "wait=10, handling=lenient")
+
+	s.Equal([]Preference{
+		{Name: "return", Value: "minimal", Params: map[string]string{"foo": "a;b"}},
+		{Name: "respond-async"},
+		{Name: "wait", Value: "10"},
+		{Name: "handling", Value: "lenient"},
+	}, p.All())
+	s.Equal("minimal", p.Return())
+	s.True(p.RespondAsync())
+	s.Equal("lenient", p.Handling())
+
+	wait, ok := p.Wait()
+	s.True(ok)
+	s.Equal(10*time.Second, wait)
+}
+
+func (s PreferSuite) TestFirstWins() {
+	p := setUpPreferences("return=minimal, return=representation")
+
+	s.Equal("minimal", p.Return())
+	s.Len(p.All(), 1)
+}
+
+func (s PreferSuite) TestInvalidWait() {
+	p := setUpPreferences("wait=soon")
+
+	_, ok := p.Wait()
+	s.False(ok)
+}
+
+func (s PreferSuite) TestQuotedComma() {
+	p := setUpPreferences(`foo="a, b", respond-async`)
+
+	pref, ok := p.Get("FOO")
+	s.True(ok)
+	s.Equal("a, b", pref.Value)
+	s.True(p.RespondAsync())
+}
+
+func (s PreferSuite) TestApplied() {
+	p := setUpPreferences(`return=minimal, respond-async, foo="a b"`)
+
+	s.True(p.Apply("Return"))
+	s.True(p.Apply("return"))
+	s.True(p.Apply("respond-async"))
+	s.True(p.Apply("foo"))
+	s.False(p.Apply("wait"))
+	s.Equal(`return=minimal, respond-async, foo="a b"`, p.Applied())
+}
+
+func (s PreferSuite) TestNegotiator() {
+	header := make(http.Header)
+	header.Set(headerPrefer, "return=minimal")
+	n := New(header)
+
+	s.True(n.Preferences() == n.Preferences())
+	s.Equal("minimal", n.Preferences().Return())
+}
+
+func (s PreferSuite) TestHandler() {
+	h := PreferHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefs := FromRequest(r).Preferences()
+		if prefs.Return() == "minimal" {
+			prefs.Apply("return")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set(headerPrefer, "return=minimal, wait=5")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	s.Equal(http.StatusNoContent, w.Code)
+	s.Equal("return=minimal", w.Header().Get(headerPreferenceApplied))
+	s.Equal(headerPrefer, w.Header().Get(headerVary))
+}
+
+func (s PreferSuite) TestHandlerNothingApplied() {
+	h := PreferHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set(headerPrefer, "return=minimal")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("", w.Header().Get(headerPreferenceApplied))
+	s.Equal(headerPrefer, w.Header().Get(headerVary))
+}
+
+func (s PreferSuite) TestHandlerWritesNothing() {
+	h := PreferHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromRequest(r).Preferences().Apply("respond-async")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set(headerPrefer, "respond-async")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("respond-async", w.Header().Get(headerPreferenceApplied))
+	s.Equal(headerPrefer, w.Header().Get(headerVary))
+}
+
+func TestPrefer(t *testing.T) {
+	suite.Run(t, new(PreferSuite))
+}
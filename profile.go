@@ -0,0 +1,163 @@
+package negotiator
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	headerAcceptProfile  = "Accept-Profile"
+	headerContentProfile = "Content-Profile"
+)
+
+// Profile returns the most preferred profile URI from the HTTP
+// Accept-Profile header, as defined in the W3C Content Negotiation by
+// Profile. URIs are compared exactly. If nothing accepted, then empty string
+// is returned.
+func (n *Negotiator) Profile(offers ...string) (bestOffer string) {
+	return n.selectOffer(headerAcceptProfile, offers)
+}
+
+// SetProfileHeaders sets the HTTP Content-Profile header of profile to h,
+// and adds a Link header with rel="profile" and "Vary: Accept-Profile".
+func SetProfileHeaders(h http.Header, profile string) {
+	h.Set(headerContentProfile, "<"+profile+">")
+	h.Add(headerLink, "<"+profile+`>; rel="profile"`)
+	h.Add(headerVary, headerAcceptProfile)
+}
+
+// parseProfiles parses an Accept-Profile header, a list of URIs in angle
+// brackets with q values such as "<urn:example:schema:v2>;q=0.9".
+func (p headerParser) parseProfiles(headerName string) (specs, error) {
+	headerVal := strings.Join(p.header[headerName], ",")
+
+	if strings.TrimSpace(headerVal) == "" {
+		return p.absent(), nil
+	}
+
+	if max := p.limits.MaxBytes; max > 0 && len(headerVal) > max {
+		if p.limits.Action != LimitTruncate {
+			return p.exceeded(headerName, "bytes", max)
+		}
+
+		// Cut after the last whole element, as URIs may contain commas.
+		headerVal = headerVal[:max]
+		cut := 0
+		for start := 0; start < len(headerVal); {
+			end := profileEnd(headerVal, start)
+			if end == len(headerVal) {
+				break
+			}
+			cut, start = end, end+1
+		}
+		headerVal = headerVal[:cut]
+	}
+
+	var ss specs
+
+	for index, start := 0, 0; start < len(headerVal); index++ {
+		end := profileEnd(headerVal, start)
+		element := headerVal[start:end]
+		offset := start
+		start = end + 1
+
+		if strings.TrimSpace(element) == "" {
+			continue
+		}
+
+		if max := p.limits.MaxElements; max > 0 && len(ss) == max {
+			if p.limits.Action != LimitTruncate {
+				return p.exceeded(headerName, "elements", max)
+			}
+			break
+		}
+
+		s, params, reason := p.parseProfile(element)
+
+		if max := p.limits.MaxParams; max > 0 && params > max {
+			if p.limits.Action != LimitTruncate {
+				return p.exceeded(headerName, "params", max)
+			}
+			continue
+		}
+
+		if reason != "" {
+			if p.strict {
+				return nil, &ParseError{Header: headerName, Index: index, Offset: offset, Reason: reason}
+			}
+			continue
+		}
+
+		if s.q > 0 {
+			s.index = len(ss)
+			ss = append(ss, s)
+		}
+	}
+
+	ss.sort()
+
+	return ss, nil
+}
+
+// parseProfile parses a single element of an Accept-Profile header. It
+// returns the number of parameters of the element, and why it is malformed.
+func (p headerParser) parseProfile(element string) (s spec, params int, reason string) {
+	element = strings.TrimSpace(element)
+
+	end := strings.IndexByte(element, '>')
+	if element[0] != '<' || end == -1 {
+		return s, 0, "invalid profile"
+	}
+
+	s = spec{val: element[1:end], q: p.defaultQ}
+
+	parts := splitQuoted(element[end+1:], ';')
+	params = len(parts) - 1
+
+	if strings.TrimSpace(parts[0]) != "" {
+		return s, params, "invalid profile"
+	}
+
+	for _, param := range parts[1:] {
+		if name, val := splitParam(param); name == "q" {
+			q, valid := parseLenientQValue(val)
+			if !valid {
+				return s, params, "invalid q"
+			}
+			if q > p.defaultQ {
+				q = p.defaultQ
+			}
+			s.q = q
+		}
+	}
+
+	return s, params, ""
+}
+
+// profileEnd returns the end of the element of an Accept-Profile header
+// starting at start: the next comma outside of angle brackets and quoted
+// strings, or the end of headerVal.
+func profileEnd(headerVal string, start int) int {
+	bracketed, quoted := false, false
+
+	for i := start; i < len(headerVal); i++ {
+		switch c := headerVal[i]; {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case bracketed:
+			bracketed = c != '>'
+		case c == '<':
+			bracketed = true
+		case c == '"':
+			quoted = true
+		case c == ',':
+			return i
+		}
+	}
+
+	return len(headerVal)
+}
@@ -0,0 +1,99 @@
+package negotiator
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProfileSuite struct {
+	suite.Suite
+}
+
+func (s ProfileSuite) TestProfile() {
+	n := setUpNegotiator(headerAcceptProfile, "<urn:example:schema:v2>;q=0.9, <http://example.org/profiles/a,b>")
+
+	s.Equal("http://example.org/profiles/a,b", n.Profile("urn:example:schema:v2", "http://example.org/profiles/a,b"))
+	s.Equal("urn:example:schema:v2", n.Profile("urn:example:schema:v1", "urn:example:schema:v2"))
+	s.Equal("", n.Profile("urn:example:schema:v1"))
+	s.Equal("http://example.org/profiles/a,b", n.Profile())
+}
+
+func (s ProfileSuite) TestSpecs() {
+	n := setUpNegotiator(headerAcceptProfile, `<urn:a>;q=0.5, <urn:b;c>; q="0.8", <urn:d>;q=0`)
+	specs := n.specs(headerAcceptProfile)
+
+	s.Len(specs, 2)
+	equalSpec(s.Assertions, specs[0], "urn:b;c", 0.8)
+	equalSpec(s.Assertions, specs[1], "urn:a", 0.5)
+}
+
+func (s ProfileSuite) TestAbsent() {
+	s.Equal("urn:a", New(nil).Profile("urn:a", "urn:b"))
+}
+
+func (s ProfileSuite) TestMalformed() {
+	n := setUpNegotiator(headerAcceptProfile, "urn:a, <urn:b>;q=abc, <urn:c")
+
+	s.Equal("", n.Profile("urn:a", "urn:b", "urn:c"))
+	s.Nil(n.Validate(headerAcceptProfile))
+
+	header := make(http.Header)
+	header.Set(headerAcceptProfile, "<urn:a>, <urn:b>;q=abc")
+
+	s.Equal(&ParseError{Header: headerAcceptProfile, Index: 1, Offset: 8, Reason: "invalid q"}, New(header, WithStrict()).Validate(headerAcceptProfile))
+}
+
+func (s ProfileSuite) TestLimits() {
+	header := make(http.Header)
+	header.Set(headerAcceptProfile, "<urn:a>;q=0.1, <urn:b>, <urn:c>")
+
+	s.Equal("urn:a", New(header, WithLimits(Limits{MaxElements: 1})).Profile("urn:a", "urn:b"))
+	s.Equal(&LimitError{Header: headerAcceptProfile, Limit: "elements", Max: 1}, New(header, WithLimits(Limits{MaxElements: 1, Action: LimitReject})).Validate(headerAcceptProfile))
+}
+
+func (s ProfileSuite) TestCaseSensitive() {
+	n := setUpNegotiator(headerAcceptProfile, "<urn:Example:A>, <urn:example:b>;q=0.5")
+
+	s.Equal("urn:example:b", n.Profile("urn:example:a", "urn:example:b"))
+	s.Equal("urn:Example:A", n.Profile("urn:example:a", "urn:Example:A"))
+}
+
+func (s ProfileSuite) TestTruncateBytes() {
+	header := make(http.Header)
+	header.Set(headerAcceptProfile, "<urn:a>;q=0.5, <http://example.org/a,b>, <urn:c>")
+
+	// The limit ends in the URI after its comma.
+	n := New(header, WithLimits(Limits{MaxBytes: 38, Action: LimitTruncate}), WithStrict())
+	s.Nil(n.Validate(headerAcceptProfile))
+
+	specs := n.specs(headerAcceptProfile)
+	s.Len(specs, 1)
+	equalSpec(s.Assertions, specs[0], "urn:a", 0.5)
+}
+
+func (s ProfileSuite) TestMaxParams() {
+	header := make(http.Header)
+	header.Set(headerAcceptProfile, "<urn:a>;a=1;b=2;q=0.5, <urn:b>;q=0.1")
+
+	specs := New(header, WithLimits(Limits{MaxParams: 2})).specs(headerAcceptProfile)
+	s.Len(specs, 1)
+	equalSpec(s.Assertions, specs[0], "urn:b", 0.1)
+
+	s.Equal(&LimitError{Header: headerAcceptProfile, Limit: "params", Max: 2},
+		New(header, WithLimits(Limits{MaxParams: 2, Action: LimitReject})).Validate(headerAcceptProfile))
+}
+
+func (s ProfileSuite) TestSetProfileHeaders() {
+	h := make(http.Header)
+	SetProfileHeaders(h, "urn:example:schema:v2")
+
+	s.Equal("<urn:example:schema:v2>", h.Get(headerContentProfile))
+	s.Equal(`<urn:example:schema:v2>; rel="profile"`, h.Get(headerLink))
+	s.Equal(headerAcceptProfile, h.Get(headerVary))
+}
+
+func TestProfile(t *testing.T) {
+	suite.Run(t, new(ProfileSuite))
+}
@@ -0,0 +1,77 @@
+package negotiator
+
+// qvalue is a quality value in thousandths, from 0 to 1000. Storing weights
+// as integers keeps their comparisons exact.
+type qvalue int
+
+const maxQ qvalue = 1000
+
+func (q qvalue) float() float64 {
+	return float64(q) / float64(maxQ)
+}
+
+// parseQValue parses s as a qvalue as defined by RFC 9110:
+//
+//	qvalue = ( "0" [ "." 0*3DIGIT ] ) / ( "1" [ "." 0*3("0") ] )
+//
+// ok is false if s doesn't match the grammar.
+func parseQValue(s string) (q qvalue, ok bool) {
+	if len(s) == 0 || len(s) > 5 || (s[0] != '0' && s[0] != '1') {
+		return
+	}
+
+	if len(s) > 1 && s[1] != '.' {
+		return
+	}
+
+	q = qvalue(s[0]-'0') * maxQ
+
+	for i, scale := 2, qvalue(100); i < len(s); i, scale = i+1, scale/10 {
+		if s[i] < '0' || s[i] > '9' || (q == maxQ && s[i] != '0') {
+			return 0, false
+		}
+
+		q += qvalue(s[i]-'0') * scale
+	}
+
+	return q, true
+}
+
+// parseLenientQValue is like parseQValue, but also accepts decimals with more
+// than three decimal places or greater than 1, which are truncated to
+// thousandths and clamped to 1. Other forms, such as exponents, signs or
+// "NaN", are still rejected.
+func parseLenientQValue(s string) (q qvalue, ok bool) {
+	if q, ok = parseQValue(s); ok {
+		return
+	}
+
+	i := 0
+	for ; i < len(s) && '0' <= s[i] && s[i] <= '9'; i++ {
+		if s[i] != '0' {
+			q = maxQ
+		}
+	}
+
+	if i == 0 {
+		return 0, false
+	}
+
+	if i < len(s) {
+		if s[i] != '.' {
+			return 0, false
+		}
+
+		for j, scale := i+1, qvalue(100); j < len(s); j, scale = j+1, scale/10 {
+			if s[j] < '0' || s[j] > '9' {
+				return 0, false
+			}
+
+			if q < maxQ {
+				q += qvalue(s[j]-'0') * scale
+			}
+		}
+	}
+
+	return q, true
+}
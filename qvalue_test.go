@@ -0,0 +1,78 @@
+package negotiator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQValue(t *testing.T) {
+	for s, want := range map[string]qvalue{
+		"0":     0,
+		"0.":    0,
+		"0.5":   500,
+		"0.05":  50,
+		"0.123": 123,
+		"1":     1000,
+		"1.":    1000,
+		"1.000": 1000,
+	} {
+		q, ok := parseQValue(s)
+
+		assert.True(t, ok, s)
+		assert.Equal(t, want, q, s)
+	}
+
+	for _, s := range []string{"", ".5", "0.1234", "1.001", "1.5", "2", "-1", "1e-1", "0x1p-2", "NaN", "Inf", "0.1a", " 0.5"} {
+		_, ok := parseQValue(s)
+
+		assert.False(t, ok, s)
+	}
+}
+
+func TestParseLenientQValue(t *testing.T) {
+	for s, want := range map[string]qvalue{
+		"0.5":     500,
+		"0.12345": 123,
+		"0.9999":  999,
+		"1.5":     1000,
+		"2":       1000,
+		"00.25":   250,
+	} {
+		q, ok := parseLenientQValue(s)
+
+		assert.True(t, ok, s)
+		assert.Equal(t, want, q, s)
+	}
+
+	for _, s := range []string{"", ".5", "-1", "+0.5", "1e-1", "0x1p-2", "NaN", "Inf", "0.1a"} {
+		_, ok := parseLenientQValue(s)
+
+		assert.False(t, ok, s)
+	}
+}
+
+func TestLenientQValues(t *testing.T) {
+	n := setUpNegotiator(headerAccept, "text/html;q=NaN, text/plain;q=1e-1, application/json;q=0.12345, image/png;q=-1")
+	specs := n.specs(headerAccept)
+
+	assert.Equal(t, 1, len(specs))
+	equalSpec(assert.New(t), specs[0], "application/json", 0.123)
+}
+
+func TestStrictQValues(t *testing.T) {
+	for val, reason := range map[string]string{
+		"text/html;q=1e-1":    "invalid q",
+		"text/html;q=0x1p-2":  "invalid q",
+		"text/html;q=NaN":     "invalid q",
+		"text/html;q=-1":      "invalid q",
+		"text/html;q=0.12345": "invalid q",
+		"text/html;q=1.5":     "q out of range",
+	} {
+		err := setUpStrictNegotiator(headerAccept, val).Validate()
+
+		if assert.IsType(t, &ParseError{}, err, val) {
+			assert.Equal(t, reason, err.(*ParseError).Reason, val)
+		}
+	}
+}
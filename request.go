@@ -0,0 +1,184 @@
+package negotiator
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// NewFromRequest creates an instance of Negotiator for r. Unlike New, the
+// Negotiator keeps r, so that negotiation can also depend on the URL, cookies
+// and context of the request.
+func NewFromRequest(r *http.Request, opts ...Option) *Negotiator {
+	n := New(r.Header, opts...)
+	n.request = r
+
+	return n
+}
+
+// Request returns the request n was created from by NewFromRequest, or nil.
+// For a Negotiator attached by Handler, it's the latest request rewritten by
+// the middleware of this package, such as FormatOverride.Handler.
+func (n *Negotiator) Request() *http.Request {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.request
+}
+
+// setRequest makes r the request of the Negotiator attached to its context,
+// if any, after a middleware rewrote it.
+func setRequest(r *http.Request) {
+	if n, ok := FromContext(r.Context()); ok {
+		n.mu.Lock()
+		n.request = r
+		n.mu.Unlock()
+	}
+}
+
+// NewContext returns a copy of ctx which carries n.
+func NewContext(ctx context.Context, n *Negotiator) context.Context {
+	return context.WithValue(ctx, negotiatorKey, n)
+}
+
+// FromContext returns the Negotiator carried by ctx, if any.
+func FromContext(ctx context.Context) (n *Negotiator, ok bool) {
+	n, ok = ctx.Value(negotiatorKey).(*Negotiator)
+	return
+}
+
+// FromRequest returns the Negotiator attached to the context of r by Handler
+// or NewContext. Otherwise it creates one with NewFromRequest and opts.
+func FromRequest(r *http.Request, opts ...Option) *Negotiator {
+	if n, ok := FromContext(r.Context()); ok {
+		return n
+	}
+
+	return NewFromRequest(r, opts...)
+}
+
+// Handler attaches a Negotiator created with opts to the context of each
+// request, so that h and the handlers it calls share one parsed instance
+// through FromRequest. If the request already carries a Negotiator, such as
+// when middleware of this package is nested, it's kept and opts are ignored.
+func Handler(h http.Handler, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := FromContext(r.Context()); ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		n := NewFromRequest(r, opts...)
+		r = r.WithContext(NewContext(r.Context(), n))
+		n.request = r
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Decision returns the offer last selected from the given header by Type,
+// Language, Encoding, Charset or their OfferSet equivalents. ok is false if
+// the header hasn't been negotiated yet.
+func (n *Negotiator) Decision(headerName string) (offer string, ok bool) {
+	n.decisionsMu.Lock()
+	defer n.decisionsMu.Unlock()
+
+	for name, decision := range n.decisions {
+		if strings.EqualFold(name, headerName) {
+			return decision, true
+		}
+	}
+
+	return
+}
+
+// Decisions returns the offers last selected from each negotiated header,
+// keyed by header name.
+func (n *Negotiator) Decisions() map[string]string {
+	n.decisionsMu.Lock()
+	defer n.decisionsMu.Unlock()
+
+	decisions := make(map[string]string, len(n.decisions))
+	for headerName, offer := range n.decisions {
+		decisions[headerName] = offer
+	}
+
+	return decisions
+}
+
+// negotiate returns the most preferred of offers from the given header
+// without recording a decision, for negotiations internal to this package.
+func (n *Negotiator) negotiate(headerName string, offers ...string) string {
+	return n.parser(headerName).selectOffer(offers, n.specs(headerName))
+}
+
+// decide records offer as the decision for the given header and returns it.
+func (n *Negotiator) decide(headerName, offer string) string {
+	n.decisionsMu.Lock()
+	defer n.decisionsMu.Unlock()
+
+	if n.decisions == nil {
+		n.decisions = make(map[string]string)
+	}
+	n.decisions[headerName] = offer
+
+	return offer
+}
+
+// headerWriter is a ResponseWriter which calls setHeaders right before the
+// response header is written.
+type headerWriter struct {
+	http.ResponseWriter
+	setHeaders  func(h http.Header)
+	wroteHeader bool
+}
+
+func newHeaderWriter(w http.ResponseWriter, setHeaders func(h http.Header)) *headerWriter {
+	return &headerWriter{ResponseWriter: w, setHeaders: setHeaders}
+}
+
+// serveWithHeaders calls h with a headerWriter around w. If h returns without
+// writing, the headers are set anyway for the implicit 200 OK response.
+func serveWithHeaders(h http.Handler, w http.ResponseWriter, r *http.Request, setHeaders func(h http.Header)) {
+	hw := newHeaderWriter(w, setHeaders)
+	h.ServeHTTP(hw, r)
+
+	if !hw.wroteHeader {
+		hw.wroteHeader = true
+		hw.setHeaders(hw.Header())
+	}
+}
+
+func (w *headerWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.setHeaders(w.Header())
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *headerWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush writes the header if needed and flushes the underlying
+// ResponseWriter if it's an http.Flusher.
+func (w *headerWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (w *headerWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
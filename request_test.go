@@ -0,0 +1,207 @@
+package negotiator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func setUpRequest(accept string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/users?format=csv", nil)
+	req.Header.Set(headerAccept, accept)
+
+	return req
+}
+
+type RequestSuite struct {
+	suite.Suite
+}
+
+func (s RequestSuite) TestNewFromRequest() {
+	req := setUpRequest("application/json")
+	n := NewFromRequest(req)
+
+	s.Equal(req, n.Request())
+	s.Equal("application/json", n.Type("text/html", "application/json"))
+	s.Nil(New(req.Header).Request())
+}
+
+func (s RequestSuite) TestContext() {
+	n := New(nil)
+	ctx := NewContext(context.Background(), n)
+
+	got, ok := FromContext(ctx)
+	s.True(ok)
+	s.Equal(n, got)
+
+	_, ok = FromContext(context.Background())
+	s.False(ok)
+}
+
+func (s RequestSuite) TestFromRequest() {
+	req := setUpRequest("application/json")
+	n := FromRequest(req)
+
+	s.Equal(req, n.Request())
+	s.False(n == FromRequest(req))
+
+	req = req.WithContext(NewContext(req.Context(), n))
+	s.True(n == FromRequest(req))
+}
+
+func (s RequestSuite) TestHandler() {
+	var n *Negotiator
+
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n = FromRequest(r)
+		s.Equal(r, n.Request())
+		s.Equal(n, FromRequest(r))
+	}), WithStrict())
+
+	h.ServeHTTP(httptest.NewRecorder(), setUpRequest("application/json"))
+
+	s.NotNil(n)
+	s.True(n.strict)
+}
+
+func (s RequestSuite) TestSharedByMiddleware() {
+	var n *Negotiator
+
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n = FromRequest(r)
+		formatOverride.Type(r, "text/html", "application/json")
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), setUpOverrideRequest("/users", "application/json"))
+
+	offer, ok := n.Decision(headerAccept)
+	s.True(ok)
+	s.Equal("application/json", offer)
+}
+
+func (s RequestSuite) TestHandlerReusesNegotiator() {
+	var n *Negotiator
+
+	h := Handler(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n = FromRequest(r)
+	}), WithStrict()))
+
+	h.ServeHTTP(httptest.NewRecorder(), setUpRequest("application/json"))
+
+	s.NotNil(n)
+	s.False(n.strict)
+}
+
+func (s RequestSuite) TestNestedMiddleware() {
+	hints := ClientHints{Accept: []string{headerSecCHDPR}}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := FromRequest(r)
+		n.DPR()
+		n.Preferences().Apply("return")
+		w.Write([]byte("hello"))
+	})
+
+	for _, handler := range []http.Handler{
+		PreferHandler(ClientHintsHandler(DigestHandler(h), hints)),
+		DigestHandler(ClientHintsHandler(PreferHandler(h), hints)),
+		ClientHintsHandler(DigestHandler(PreferHandler(h)), hints),
+	} {
+		req := setUpRequest("")
+		req.Header.Set(headerPrefer, "return=minimal")
+		req.Header.Set(headerSecCHDPR, "2")
+		req.Header.Set(headerWantContentDigest, "sha-256=1")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		s.Equal("return=minimal", w.Header().Get(headerPreferenceApplied))
+		s.Contains(w.Header().Values(headerVary), headerSecCHDPR)
+		s.Contains(w.Header().Values(headerVary), headerPrefer)
+		s.Equal(headerSecCHDPR, w.Header().Get(headerAcceptCH))
+		s.Contains(w.Header().Get(headerContentDigest), "sha-256=:")
+		s.Equal("hello", w.Body.String())
+	}
+}
+
+func (s RequestSuite) TestDecisions() {
+	header := make(http.Header)
+	header.Set(headerAccept, "application/json")
+	header.Set(headerAcceptLanguage, "de")
+	n := New(header)
+
+	_, ok := n.Decision(headerAccept)
+	s.False(ok)
+
+	n.Type("text/html", "application/json")
+	n.SelectLanguage(NewOfferSet("en", "de"))
+
+	offer, ok := n.Decision("accept")
+	s.True(ok)
+	s.Equal("application/json", offer)
+	s.Equal(map[string]string{headerAccept: "application/json", headerAcceptLanguage: "de"}, n.Decisions())
+}
+
+func (s RequestSuite) TestDecisionsCached() {
+	header := make(http.Header)
+	header.Set(headerAccept, "application/json")
+	cache := NewCache(8, true)
+	set := NewOfferSet("application/json")
+
+	New(header, WithCache(cache)).SelectType(set)
+	n := New(header, WithCache(cache))
+	n.SelectType(set)
+
+	s.Equal(map[string]string{headerAccept: "application/json"}, n.Decisions())
+}
+
+func (s RequestSuite) TestInternalNegotiationsNotRecorded() {
+	req := setUpRequest("text/html, application/vnd.acme.v1+json")
+	n := NewFromRequest(req)
+	req = req.WithContext(NewContext(req.Context(), n))
+
+	s.Equal("application/vnd.acme.v1+json", n.Type("application/vnd.acme.v1+json"))
+
+	MultipleChoices(httptest.NewRecorder(), req, []Variant{{URI: "/a", Type: "text/html"}})
+	n.Version(acmeVersioning)
+
+	s.Equal(map[string]string{headerAccept: "application/vnd.acme.v1+json"}, n.Decisions())
+}
+
+func (s RequestSuite) TestRequestAfterOverride() {
+	var n *Negotiator
+	var req *http.Request
+
+	h := Handler(FormatOverride{Extensions: true}.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, req = FromRequest(r), r
+	})))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users.json", nil))
+
+	s.Equal(req, n.Request())
+	s.Equal("/users", n.Request().URL.Path)
+}
+
+func (s RequestSuite) TestHeaderWriterFlush() {
+	w := httptest.NewRecorder()
+
+	serveWithHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		s.True(ok)
+		f.Flush()
+		w.Header().Set("X-After", "1")
+	}), w, setUpRequest(""), func(h http.Header) {
+		h.Set("X-Pending", "1")
+	})
+
+	s.True(w.Flushed)
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("1", w.Result().Header.Get("X-Pending"))
+	s.Equal("", w.Result().Header.Get("X-After"))
+}
+
+func TestRequest(t *testing.T) {
+	suite.Run(t, new(RequestSuite))
+}
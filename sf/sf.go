@@ -0,0 +1,768 @@
+// Package sf parses and serializes structured fields for HTTP, as defined in
+// RFC 8941 and RFC 9651.
+package sf
+
+import (
+	"encoding/base64"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Token is a token of a structured field, as opposed to a string.
+type Token string
+
+// Date is a date of a structured field, in seconds since the Unix epoch.
+type Date int64
+
+// DisplayString is a display string of a structured field, a string of
+// Unicode characters as opposed to the ASCII characters of a string.
+type DisplayString string
+
+// Param is a parameter of an Item or an InnerList.
+type Param struct {
+	Key string
+	// Value is a bare item, see Item.
+	Value interface{}
+}
+
+// Params are the ordered parameters of an Item or an InnerList.
+type Params []Param
+
+// Get returns the value of the parameter with the given key.
+func (ps Params) Get(key string) (val interface{}, ok bool) {
+	for _, p := range ps {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+
+	return
+}
+
+func (ps Params) set(key string, val interface{}) Params {
+	for i, p := range ps {
+		if p.Key == key {
+			ps[i].Value = val
+			return ps
+		}
+	}
+
+	return append(ps, Param{Key: key, Value: val})
+}
+
+// Member is a member of a List or a Dictionary: an Item or an InnerList.
+type Member interface {
+	member()
+}
+
+// Item is an item of a structured field.
+type Item struct {
+	// Value is the bare item: an int64 (integer), a float64 (decimal), a
+	// string, a Token, a []byte (byte sequence), a bool, a Date or a
+	// DisplayString. Serializing also accepts an int.
+	Value  interface{}
+	Params Params
+}
+
+// InnerList is an inner list of a structured field.
+type InnerList struct {
+	Items  []Item
+	Params Params
+}
+
+func (Item) member()      {}
+func (InnerList) member() {}
+
+// List is a list structured field.
+type List []Member
+
+// DictMember is a member of a Dictionary.
+type DictMember struct {
+	Key   string
+	Value Member
+}
+
+// Dictionary is a dictionary structured field, in the order of its keys.
+type Dictionary []DictMember
+
+// Get returns the member with the given key.
+func (d Dictionary) Get(key string) (m Member, ok bool) {
+	for _, dm := range d {
+		if dm.Key == key {
+			return dm.Value, true
+		}
+	}
+
+	return
+}
+
+// Error reports a structured field which can't be parsed or serialized.
+type Error struct {
+	// Offset is the offset of the error in the parsed value, or -1 when
+	// serializing.
+	Offset int
+	// Reason describes the error.
+	Reason string
+}
+
+func (e *Error) Error() string {
+	if e.Offset < 0 {
+		return "sf: invalid structured field: " + e.Reason
+	}
+
+	return "sf: malformed structured field at offset " + strconv.Itoa(e.Offset) + ": " + e.Reason
+}
+
+// ParseItem parses an item structured field.
+func ParseItem(s string) (Item, error) {
+	p := &parser{s: s}
+	p.skipSP()
+
+	item, err := p.item()
+	if err != nil {
+		return Item{}, err
+	}
+
+	return item, p.end()
+}
+
+// ParseList parses a list structured field.
+func ParseList(s string) (List, error) {
+	p := &parser{s: s}
+	p.skipSP()
+
+	var l List
+
+	for !p.eof() {
+		m, err := p.member()
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, m)
+
+		if more, err := p.next(); err != nil {
+			return nil, err
+		} else if !more {
+			break
+		}
+	}
+
+	return l, p.end()
+}
+
+// ParseDictionary parses a dictionary structured field. Of duplicate keys,
+// the last value is used, in the position of the first.
+func ParseDictionary(s string) (Dictionary, error) {
+	p := &parser{s: s}
+	p.skipSP()
+
+	var d Dictionary
+
+	for !p.eof() {
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+
+		var m Member
+		if p.peek() == '=' {
+			p.i++
+			if m, err = p.member(); err != nil {
+				return nil, err
+			}
+		} else {
+			params, err := p.params()
+			if err != nil {
+				return nil, err
+			}
+			m = Item{Value: true, Params: params}
+		}
+
+		d = d.set(key, m)
+
+		if more, err := p.next(); err != nil {
+			return nil, err
+		} else if !more {
+			break
+		}
+	}
+
+	return d, p.end()
+}
+
+func (d Dictionary) set(key string, m Member) Dictionary {
+	for i, dm := range d {
+		if dm.Key == key {
+			d[i].Value = m
+			return d
+		}
+	}
+
+	return append(d, DictMember{Key: key, Value: m})
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+func (p *parser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.s[p.i]
+}
+
+func (p *parser) fail(reason string) error {
+	return &Error{Offset: p.i, Reason: reason}
+}
+
+func (p *parser) skipSP() {
+	for p.peek() == ' ' {
+		p.i++
+	}
+}
+
+func (p *parser) skipOWS() {
+	for c := p.peek(); c == ' ' || c == '\t'; c = p.peek() {
+		p.i++
+	}
+}
+
+func (p *parser) end() error {
+	p.skipSP()
+	if !p.eof() {
+		return p.fail("unexpected character")
+	}
+
+	return nil
+}
+
+// next consumes the separator between members. more is false at the end of
+// the value.
+func (p *parser) next() (more bool, err error) {
+	p.skipOWS()
+	if p.eof() {
+		return false, nil
+	}
+
+	if p.peek() != ',' {
+		return false, p.fail("expected comma")
+	}
+	p.i++
+	p.skipOWS()
+
+	if p.eof() {
+		return false, p.fail("trailing comma")
+	}
+
+	return true, nil
+}
+
+func (p *parser) member() (Member, error) {
+	if p.peek() == '(' {
+		return p.innerList()
+	}
+
+	return p.item()
+}
+
+func (p *parser) innerList() (InnerList, error) {
+	var l InnerList
+	p.i++
+
+	for !p.eof() {
+		p.skipSP()
+
+		if p.peek() == ')' {
+			p.i++
+
+			params, err := p.params()
+			l.Params = params
+
+			return l, err
+		}
+
+		item, err := p.item()
+		if err != nil {
+			return l, err
+		}
+		l.Items = append(l.Items, item)
+
+		if c := p.peek(); c != ' ' && c != ')' {
+			return l, p.fail("expected space or closing parenthesis")
+		}
+	}
+
+	return l, p.fail("unterminated inner list")
+}
+
+func (p *parser) item() (Item, error) {
+	val, err := p.bareItem()
+	if err != nil {
+		return Item{}, err
+	}
+
+	params, err := p.params()
+
+	return Item{Value: val, Params: params}, err
+}
+
+func (p *parser) params() (Params, error) {
+	var params Params
+
+	for p.peek() == ';' {
+		p.i++
+		p.skipSP()
+
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+
+		var val interface{} = true
+		if p.peek() == '=' {
+			p.i++
+			if val, err = p.bareItem(); err != nil {
+				return nil, err
+			}
+		}
+
+		params = params.set(key, val)
+	}
+
+	return params, nil
+}
+
+func (p *parser) key() (string, error) {
+	if c := p.peek(); !isLCAlpha(c) && c != '*' {
+		return "", p.fail("invalid key")
+	}
+
+	start := p.i
+	for c := p.peek(); isLCAlpha(c) || isDigit(c) || strings.IndexByte("_-.*", c) != -1; c = p.peek() {
+		p.i++
+	}
+
+	return p.s[start:p.i], nil
+}
+
+func (p *parser) bareItem() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '-' || isDigit(c):
+		return p.number()
+	case c == '"':
+		return p.string()
+	case c == '*' || isAlpha(c):
+		return p.token(), nil
+	case c == ':':
+		return p.byteSequence()
+	case c == '?':
+		return p.boolean()
+	case c == '@':
+		return p.date()
+	case c == '%':
+		return p.displayString()
+	}
+
+	return nil, p.fail("invalid item")
+}
+
+func (p *parser) number() (interface{}, error) {
+	start := p.i
+	if p.peek() == '-' {
+		p.i++
+	}
+	if !isDigit(p.peek()) {
+		return nil, p.fail("expected digit")
+	}
+
+	digits, dot := 0, -1
+
+	for c := p.peek(); isDigit(c) || (c == '.' && dot == -1); c = p.peek() {
+		if c == '.' {
+			if digits > 12 {
+				return nil, p.fail("decimal with too many integer digits")
+			}
+			dot = digits
+		} else {
+			digits++
+		}
+		p.i++
+
+		if digits > 15 {
+			return nil, p.fail("number with too many digits")
+		}
+	}
+
+	num := p.s[start:p.i]
+
+	if dot == -1 {
+		i, err := strconv.ParseInt(num, 10, 64)
+		if err != nil {
+			return nil, p.fail("invalid integer")
+		}
+		return i, nil
+	}
+
+	if fraction := digits - dot; fraction == 0 || fraction > 3 {
+		return nil, p.fail("invalid decimal fraction")
+	}
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return nil, p.fail("invalid decimal")
+	}
+
+	return f, nil
+}
+
+func (p *parser) string() (string, error) {
+	var b strings.Builder
+	p.i++
+
+	for !p.eof() {
+		c := p.s[p.i]
+		p.i++
+
+		switch {
+		case c == '\\':
+			if c := p.peek(); c != '"' && c != '\\' {
+				return "", p.fail("invalid escape")
+			}
+			b.WriteByte(p.s[p.i])
+			p.i++
+		case c == '"':
+			return b.String(), nil
+		case c < 0x20 || c > 0x7e:
+			p.i--
+			return "", p.fail("invalid character in string")
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", p.fail("unterminated string")
+}
+
+func (p *parser) token() Token {
+	start := p.i
+	p.i++
+
+	for c := p.peek(); isTChar(c) || c == ':' || c == '/'; c = p.peek() {
+		p.i++
+	}
+
+	return Token(p.s[start:p.i])
+}
+
+func (p *parser) byteSequence() ([]byte, error) {
+	p.i++
+
+	end := strings.IndexByte(p.s[p.i:], ':')
+	if end == -1 {
+		return nil, p.fail("unterminated byte sequence")
+	}
+
+	encoded := p.s[p.i : p.i+end]
+	for i := 0; i < len(encoded); i++ {
+		if c := encoded[i]; !isAlpha(c) && !isDigit(c) && c != '+' && c != '/' && c != '=' {
+			p.i += i
+			return nil, p.fail("invalid character in byte sequence")
+		}
+	}
+
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		if b, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "=")); err != nil {
+			return nil, p.fail("invalid base64")
+		}
+	}
+
+	p.i += end + 1
+
+	return b, nil
+}
+
+func (p *parser) boolean() (bool, error) {
+	p.i++
+
+	switch p.peek() {
+	case '1':
+		p.i++
+		return true, nil
+	case '0':
+		p.i++
+		return false, nil
+	}
+
+	return false, p.fail("invalid boolean")
+}
+
+func (p *parser) date() (Date, error) {
+	p.i++
+
+	n, err := p.number()
+	if err != nil {
+		return 0, err
+	}
+
+	i, ok := n.(int64)
+	if !ok {
+		return 0, p.fail("date with fraction")
+	}
+
+	return Date(i), nil
+}
+
+func (p *parser) displayString() (DisplayString, error) {
+	p.i++
+	if p.peek() != '"' {
+		return "", p.fail("expected quote")
+	}
+	p.i++
+
+	var b []byte
+
+	for !p.eof() {
+		c := p.s[p.i]
+
+		switch {
+		case c == '%':
+			if p.i+2 >= len(p.s) || !isLCHex(p.s[p.i+1]) || !isLCHex(p.s[p.i+2]) {
+				return "", p.fail("invalid percent encoding")
+			}
+			n, _ := strconv.ParseUint(p.s[p.i+1:p.i+3], 16, 8)
+			b = append(b, byte(n))
+			p.i += 3
+		case c == '"':
+			if !utf8.Valid(b) {
+				return "", p.fail("invalid UTF-8 in display string")
+			}
+			p.i++
+			return DisplayString(b), nil
+		case c < 0x20 || c > 0x7e:
+			return "", p.fail("invalid character in display string")
+		default:
+			b = append(b, c)
+			p.i++
+		}
+	}
+
+	return "", p.fail("unterminated display string")
+}
+
+// SerializeItem serializes an item structured field.
+func SerializeItem(item Item) (string, error) {
+	var b strings.Builder
+	err := serializeItem(&b, item)
+
+	return b.String(), err
+}
+
+// SerializeList serializes a list structured field.
+func SerializeList(l List) (string, error) {
+	var b strings.Builder
+
+	for i, m := range l {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := serializeMember(&b, m); err != nil {
+			return "", err
+		}
+	}
+
+	return b.String(), nil
+}
+
+// SerializeDictionary serializes a dictionary structured field.
+func SerializeDictionary(d Dictionary) (string, error) {
+	var b strings.Builder
+
+	for i, dm := range d {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := serializeKey(&b, dm.Key); err != nil {
+			return "", err
+		}
+
+		if item, ok := dm.Value.(Item); ok && item.Value == true {
+			if err := serializeParams(&b, item.Params); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		b.WriteByte('=')
+		if err := serializeMember(&b, dm.Value); err != nil {
+			return "", err
+		}
+	}
+
+	return b.String(), nil
+}
+
+func serializeMember(b *strings.Builder, m Member) error {
+	switch m := m.(type) {
+	case Item:
+		return serializeItem(b, m)
+	case InnerList:
+		b.WriteByte('(')
+		for i, item := range m.Items {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			if err := serializeItem(b, item); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(')')
+
+		return serializeParams(b, m.Params)
+	}
+
+	return &Error{Offset: -1, Reason: "invalid member"}
+}
+
+func serializeItem(b *strings.Builder, item Item) error {
+	if err := serializeBareItem(b, item.Value); err != nil {
+		return err
+	}
+
+	return serializeParams(b, item.Params)
+}
+
+func serializeParams(b *strings.Builder, params Params) error {
+	for _, p := range params {
+		b.WriteByte(';')
+		if err := serializeKey(b, p.Key); err != nil {
+			return err
+		}
+
+		if p.Value != true {
+			b.WriteByte('=')
+			if err := serializeBareItem(b, p.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func serializeKey(b *strings.Builder, key string) error {
+	p := &parser{s: key}
+	if _, err := p.key(); err != nil || !p.eof() {
+		return &Error{Offset: -1, Reason: "invalid key " + strconv.Quote(key)}
+	}
+
+	b.WriteString(key)
+
+	return nil
+}
+
+func serializeBareItem(b *strings.Builder, val interface{}) error {
+	switch val := val.(type) {
+	case int:
+		return serializeBareItem(b, int64(val))
+	case int64:
+		if val > 999999999999999 || val < -999999999999999 {
+			return &Error{Offset: -1, Reason: "integer out of range"}
+		}
+		b.WriteString(strconv.FormatInt(val, 10))
+	case float64:
+		val = math.RoundToEven(val*1000) / 1000
+		if math.IsNaN(val) || math.Abs(val) >= 1e12 {
+			return &Error{Offset: -1, Reason: "decimal out of range"}
+		}
+
+		s := strconv.FormatFloat(val, 'f', -1, 64)
+		if strings.IndexByte(s, '.') == -1 {
+			s += ".0"
+		}
+		b.WriteString(s)
+	case string:
+		b.WriteByte('"')
+		for i := 0; i < len(val); i++ {
+			c := val[i]
+			if c < 0x20 || c > 0x7e {
+				return &Error{Offset: -1, Reason: "invalid character in string"}
+			}
+			if c == '"' || c == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		}
+		b.WriteByte('"')
+	case Token:
+		p := &parser{s: string(val)}
+		if c := p.peek(); (c != '*' && !isAlpha(c)) || p.token() != val {
+			return &Error{Offset: -1, Reason: "invalid token " + strconv.Quote(string(val))}
+		}
+		b.WriteString(string(val))
+	case []byte:
+		b.WriteByte(':')
+		b.WriteString(base64.StdEncoding.EncodeToString(val))
+		b.WriteByte(':')
+	case bool:
+		if val {
+			b.WriteString("?1")
+		} else {
+			b.WriteString("?0")
+		}
+	case Date:
+		if val > 999999999999999 || val < -999999999999999 {
+			return &Error{Offset: -1, Reason: "date out of range"}
+		}
+		b.WriteByte('@')
+		b.WriteString(strconv.FormatInt(int64(val), 10))
+	case DisplayString:
+		if !utf8.ValidString(string(val)) {
+			return &Error{Offset: -1, Reason: "invalid UTF-8 in display string"}
+		}
+		b.WriteString(`%"`)
+		for i := 0; i < len(val); i++ {
+			if c := val[i]; c == '%' || c == '"' || c < 0x20 || c > 0x7e {
+				b.WriteByte('%')
+				b.WriteString(strconv.FormatUint(uint64(c)>>4, 16) + strconv.FormatUint(uint64(c)&0xf, 16))
+			} else {
+				b.WriteByte(c)
+			}
+		}
+		b.WriteByte('"')
+	default:
+		return &Error{Offset: -1, Reason: "unsupported item type"}
+	}
+
+	return nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isLCAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isLCHex(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f')
+}
+
+func isAlpha(c byte) bool {
+	return isLCAlpha(c) || ('A' <= c && c <= 'Z')
+}
+
+// isTChar reports whether c is a token character as defined in RFC 9110.
+func isTChar(c byte) bool {
+	return isAlpha(c) || isDigit(c) || strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1
+}
@@ -0,0 +1,392 @@
+package sf
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	// vectorsDir holds the hand-written tests of this package.
+	vectorsDir = filepath.Join("testdata", "vectors")
+	// suiteDir holds the official test suite,
+	// https://github.com/httpwg/structured-field-tests, if it's vendored.
+	suiteDir = filepath.Join("testdata", "structured-field-tests")
+)
+
+// vector is a test in the format of the official test suite.
+type vector struct {
+	Name       string      `json:"name"`
+	Raw        []string    `json:"raw"`
+	HeaderType string      `json:"header_type"`
+	Expected   interface{} `json:"expected"`
+	MustFail   bool        `json:"must_fail"`
+	CanFail    bool        `json:"can_fail"`
+	Canonical  []string    `json:"canonical"`
+}
+
+func readVectors(t *testing.T, file string) []vector {
+	data, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+
+	var vectors []vector
+
+	// Numbers are kept as written, to tell integers from decimals.
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	assert.Nil(t, d.Decode(&vectors), file)
+
+	return vectors
+}
+
+// parse parses raw as the given header type, and returns the result in the
+// JSON form of the test suite and its serialization.
+func parse(headerType, raw string) (result interface{}, serialized string, err error) {
+	switch headerType {
+	case "item":
+		var item Item
+		if item, err = ParseItem(raw); err != nil {
+			return
+		}
+		result = itemJSON(item)
+		serialized, err = SerializeItem(item)
+	case "list":
+		var l List
+		if l, err = ParseList(raw); err != nil {
+			return
+		}
+		members := []interface{}{}
+		for _, m := range l {
+			members = append(members, memberJSON(m))
+		}
+		result = members
+		serialized, err = SerializeList(l)
+	case "dictionary":
+		var d Dictionary
+		if d, err = ParseDictionary(raw); err != nil {
+			return
+		}
+		members := []interface{}{}
+		for _, dm := range d {
+			members = append(members, []interface{}{dm.Key, memberJSON(dm.Value)})
+		}
+		result = members
+		serialized, err = SerializeDictionary(d)
+	}
+
+	return
+}
+
+// serialize serializes expected, in the JSON form of the test suite, as the
+// given header type.
+func serialize(headerType string, expected interface{}) (string, error) {
+	switch headerType {
+	case "item":
+		return SerializeItem(jsonItem(expected))
+	case "list":
+		var l List
+		for _, m := range expected.([]interface{}) {
+			l = append(l, jsonMember(m))
+		}
+		return SerializeList(l)
+	}
+
+	var d Dictionary
+	for _, dm := range expected.([]interface{}) {
+		pair := dm.([]interface{})
+		d = append(d, DictMember{Key: pair[0].(string), Value: jsonMember(pair[1])})
+	}
+
+	return SerializeDictionary(d)
+}
+
+func memberJSON(m Member) interface{} {
+	if l, ok := m.(InnerList); ok {
+		items := []interface{}{}
+		for _, item := range l.Items {
+			items = append(items, itemJSON(item))
+		}
+		return []interface{}{items, paramsJSON(l.Params)}
+	}
+
+	return itemJSON(m.(Item))
+}
+
+func jsonMember(v interface{}) Member {
+	pair := v.([]interface{})
+
+	if items, ok := pair[0].([]interface{}); ok {
+		l := InnerList{Items: []Item{}, Params: jsonParams(pair[1])}
+		for _, item := range items {
+			l.Items = append(l.Items, jsonItem(item))
+		}
+		return l
+	}
+
+	return jsonItem(v)
+}
+
+func itemJSON(item Item) interface{} {
+	return []interface{}{bareItemJSON(item.Value), paramsJSON(item.Params)}
+}
+
+func jsonItem(v interface{}) Item {
+	pair := v.([]interface{})
+	return Item{Value: jsonBareItem(pair[0]), Params: jsonParams(pair[1])}
+}
+
+func paramsJSON(params Params) interface{} {
+	ps := []interface{}{}
+	for _, p := range params {
+		ps = append(ps, []interface{}{p.Key, bareItemJSON(p.Value)})
+	}
+
+	return ps
+}
+
+func jsonParams(v interface{}) Params {
+	var params Params
+	for _, p := range v.([]interface{}) {
+		pair := p.([]interface{})
+		params = append(params, Param{Key: pair[0].(string), Value: jsonBareItem(pair[1])})
+	}
+
+	return params
+}
+
+func bareItemJSON(val interface{}) interface{} {
+	switch val := val.(type) {
+	case int64:
+		return float64(val)
+	case Token:
+		return map[string]interface{}{"__type": "token", "value": string(val)}
+	case []byte:
+		return map[string]interface{}{"__type": "binary", "value": base32.StdEncoding.EncodeToString(val)}
+	case Date:
+		return map[string]interface{}{"__type": "date", "value": float64(val)}
+	case DisplayString:
+		return map[string]interface{}{"__type": "displaystring", "value": string(val)}
+	}
+
+	return val
+}
+
+func jsonBareItem(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			f, _ := v.Float64()
+			return f
+		}
+		i, _ := v.Int64()
+		return i
+	case map[string]interface{}:
+		switch v["__type"] {
+		case "token":
+			return Token(v["value"].(string))
+		case "binary":
+			b, _ := base32.StdEncoding.DecodeString(v["value"].(string))
+			return b
+		case "date":
+			i, _ := v["value"].(json.Number).Int64()
+			return Date(i)
+		case "displaystring":
+			return DisplayString(v["value"].(string))
+		}
+	}
+
+	return v
+}
+
+// numbersToFloats returns v with the JSON numbers of the test suite as
+// float64, to compare them with parsed values in bareItemJSON form.
+func numbersToFloats(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		vs := make([]interface{}, len(v))
+		for i := range v {
+			vs[i] = numbersToFloats(v[i])
+		}
+		return vs
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k := range v {
+			m[k] = numbersToFloats(v[k])
+		}
+		return m
+	}
+
+	return v
+}
+
+// vectorFiles returns the files of the hand-written tests matching pattern
+// and the files of the official test suite matching suitePattern.
+func vectorFiles(t *testing.T, pattern, suitePattern string) []string {
+	files, err := filepath.Glob(filepath.Join(vectorsDir, pattern))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+
+	suiteFiles, err := filepath.Glob(filepath.Join(suiteDir, suitePattern))
+	assert.Nil(t, err)
+	if len(suiteFiles) == 0 {
+		t.Log("the official test suite isn't vendored in " + suiteDir)
+	}
+
+	return append(files, suiteFiles...)
+}
+
+func TestParseVectors(t *testing.T) {
+	files := vectorFiles(t, "parse-*.json", "*.json")
+
+	for _, file := range files {
+		for _, test := range readVectors(t, file) {
+			name := filepath.Base(file) + ": " + test.Name
+			result, serialized, err := parse(test.HeaderType, strings.Join(test.Raw, ", "))
+
+			if test.MustFail {
+				assert.NotNil(t, err, name)
+				continue
+			}
+			if err != nil && test.CanFail {
+				continue
+			}
+			if !assert.Nil(t, err, name) {
+				continue
+			}
+
+			assert.Equal(t, numbersToFloats(test.Expected), result, name)
+
+			canonical := test.Raw
+			if test.Canonical != nil {
+				canonical = test.Canonical
+			}
+			assert.Equal(t, strings.Join(canonical, ", "), serialized, name)
+		}
+	}
+}
+
+func TestSerializeVectors(t *testing.T) {
+	files := vectorFiles(t, "serialize-*.json", filepath.Join("serialisation-tests", "*.json"))
+
+	for _, file := range files {
+		for _, test := range readVectors(t, file) {
+			name := filepath.Base(file) + ": " + test.Name
+			serialized, err := serialize(test.HeaderType, test.Expected)
+
+			if test.MustFail {
+				assert.NotNil(t, err, name)
+				continue
+			}
+			if !assert.Nil(t, err, name) {
+				continue
+			}
+
+			assert.Equal(t, strings.Join(test.Canonical, ", "), serialized, name)
+		}
+	}
+}
+
+type SFSuite struct {
+	suite.Suite
+}
+
+func (s SFSuite) TestParseItem() {
+	item, err := ParseItem(`text/html;q=0.5;level="1"`)
+
+	s.Nil(err)
+	s.Equal(Item{Value: Token("text/html"), Params: Params{{Key: "q", Value: 0.5}, {Key: "level", Value: "1"}}}, item)
+
+	q, ok := item.Params.Get("q")
+	s.True(ok)
+	s.Equal(0.5, q)
+}
+
+func (s SFSuite) TestParseDictionary() {
+	d, err := ParseDictionary("sha-256=:AAAA:, sha-512=?0")
+
+	s.Nil(err)
+
+	m, ok := d.Get("sha-256")
+	s.True(ok)
+	s.Equal(Item{Value: []byte{0, 0, 0}}, m)
+
+	_, ok = d.Get("md5")
+	s.False(ok)
+}
+
+func (s SFSuite) TestParseError() {
+	_, err := ParseList("a, b,")
+
+	s.Equal(&Error{Offset: 5, Reason: "trailing comma"}, err)
+	s.EqualError(err, "sf: malformed structured field at offset 5: trailing comma")
+}
+
+func (s SFSuite) TestSerialize() {
+	str, err := SerializeList(List{
+		Item{Value: 1, Params: Params{{Key: "a", Value: true}}},
+		InnerList{Items: []Item{{Value: "x"}, {Value: Token("y")}}, Params: Params{{Key: "q", Value: 0.1235}}},
+		Item{Value: 2.0},
+		Item{Value: []byte("hello")},
+	})
+
+	s.Nil(err)
+	s.Equal(`1;a, ("x" y);q=0.124, 2.0, :aGVsbG8=:`, str)
+}
+
+func (s SFSuite) TestSerializeError() {
+	_, err := SerializeItem(Item{Value: int64(1e15)})
+	s.EqualError(err, "sf: invalid structured field: integer out of range")
+
+	_, err = SerializeItem(Item{Value: Token("a b")})
+	s.NotNil(err)
+
+	_, err = SerializeItem(Item{Value: "\n"})
+	s.NotNil(err)
+
+	_, err = SerializeItem(Item{Value: 1, Params: Params{{Key: "A", Value: true}}})
+	s.NotNil(err)
+
+	_, err = SerializeDictionary(Dictionary{{Key: "", Value: Item{Value: true}}})
+	s.NotNil(err)
+
+	_, err = SerializeItem(Item{Value: uint8(1)})
+	s.NotNil(err)
+}
+
+func (s SFSuite) TestDateAndDisplayString() {
+	l, err := ParseList(`@1659578233, %"f%c3%bc%c3%bc"`)
+
+	s.Nil(err)
+	s.Equal(List{Item{Value: Date(1659578233)}, Item{Value: DisplayString("füü")}}, l)
+
+	str, err := SerializeList(List{Item{Value: Date(-1)}, Item{Value: DisplayString(`100% "sûr"`)}})
+
+	s.Nil(err)
+	s.Equal(`@-1, %"100%25 %22s%c3%bbr%22"`, str)
+
+	_, err = ParseItem("@1.5")
+	s.NotNil(err)
+
+	_, err = ParseItem(`%"%C3%BC"`)
+	s.NotNil(err)
+
+	_, err = ParseItem(`%"%ff"`)
+	s.NotNil(err)
+
+	_, err = SerializeItem(Item{Value: DisplayString("\xff")})
+	s.NotNil(err)
+}
+
+func TestSF(t *testing.T) {
+	suite.Run(t, new(SFSuite))
+}
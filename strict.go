@@ -0,0 +1,32 @@
+package negotiator
+
+import (
+	"strconv"
+)
+
+// WithStrict makes a Negotiator reject malformed headers, such as elements
+// with an invalid q or a media type without subtype, instead of skipping the
+// malformed elements. A rejected header accepts nothing, and Validate returns
+// a *ParseError for it.
+func WithStrict() Option {
+	return func(n *Negotiator) {
+		n.strict = true
+	}
+}
+
+// ParseError reports a malformed header in strict mode.
+type ParseError struct {
+	// Header is the name of the header.
+	Header string
+	// Index is the index of the malformed element, counting from 0.
+	Index int
+	// Offset is the byte offset of the malformed part in the header value.
+	Offset int
+	// Reason describes what's malformed, e.g. "invalid q".
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return "negotiator: malformed " + e.Header + " header at element " + strconv.Itoa(e.Index) +
+		" (offset " + strconv.Itoa(e.Offset) + "): " + e.Reason
+}
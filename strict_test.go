@@ -0,0 +1,74 @@
+package negotiator
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func setUpStrictNegotiator(headerName, val string) *Negotiator {
+	header := make(http.Header)
+	header.Set(headerName, val)
+
+	return New(header, WithStrict())
+}
+
+type StrictSuite struct {
+	suite.Suite
+}
+
+func (s StrictSuite) TestWellFormed() {
+	n := setUpStrictNegotiator(headerAccept, "text/html, application/*;q=0.9, text/plain;format=flowed;q=0.5")
+
+	s.Nil(n.Validate())
+	s.Equal("text/html", n.Type("text/html"))
+}
+
+func (s StrictSuite) TestInvalidQ() {
+	n := setUpStrictNegotiator(headerAccept, "text/html, application/json;q=abc")
+
+	s.Equal(&ParseError{Header: headerAccept, Index: 1, Offset: 28, Reason: "invalid q"}, n.Validate())
+	s.Equal("", n.Type("text/html"))
+}
+
+func (s StrictSuite) TestQOutOfRange() {
+	n := setUpStrictNegotiator(headerAcceptLanguage, "en;q=1.5")
+
+	s.Equal(&ParseError{Header: headerAcceptLanguage, Index: 0, Offset: 3, Reason: "q out of range"}, n.Validate())
+}
+
+func (s StrictSuite) TestMissingSubtype() {
+	n := setUpStrictNegotiator(headerAccept, "text/html,  text/")
+
+	s.Equal(&ParseError{Header: headerAccept, Index: 1, Offset: 12, Reason: "missing subtype"}, n.Validate())
+}
+
+func (s StrictSuite) TestEmptyElement() {
+	n := setUpStrictNegotiator(headerAcceptEncoding, "gzip,,br")
+
+	s.Equal(&ParseError{Header: headerAcceptEncoding, Index: 1, Offset: 5, Reason: "empty element"}, n.Validate())
+}
+
+func (s StrictSuite) TestInvalidParameter() {
+	n := setUpStrictNegotiator(headerAccept, "text/html;level")
+
+	s.Equal(&ParseError{Header: headerAccept, Index: 0, Offset: 10, Reason: "invalid parameter"}, n.Validate())
+}
+
+func (s StrictSuite) TestErrorMessage() {
+	n := setUpStrictNegotiator(headerAccept, "text/html, application/json;q=abc")
+
+	s.EqualError(n.Validate(), "negotiator: malformed Accept header at element 1 (offset 28): invalid q")
+}
+
+func (s StrictSuite) TestLenientByDefault() {
+	n := setUpNegotiator(headerAccept, "text/html, application/json;q=abc, text/")
+
+	s.Nil(n.Validate())
+	s.Equal("text/html", n.Type("text/html", "application/json"))
+}
+
+func TestStrict(t *testing.T) {
+	suite.Run(t, new(StrictSuite))
+}
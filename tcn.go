@@ -0,0 +1,230 @@
+package negotiator
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	headerNegotiate       = "Negotiate"
+	headerAlternates      = "Alternates"
+	headerTCN             = "TCN"
+	headerVary            = "Vary"
+	headerContentLocation = "Content-Location"
+)
+
+// Variant represents a variant of a transparently negotiable resource, as
+// described in RFC 2295.
+type Variant struct {
+	// URI is the variant URI, relative to the negotiable resource.
+	URI string
+	// SourceQuality is the source quality of the variant in (0, 1].
+	// A zero SourceQuality is treated as 1.
+	SourceQuality float64
+	// Type is the media type of the variant, or empty if unknown.
+	Type string
+	// Charset is the charset of the variant, or empty if not applicable.
+	Charset string
+	// Language is the language of the variant, or empty if not applicable.
+	Language string
+	// Length is the length of the variant in bytes, or 0 if unknown.
+	Length int64
+}
+
+func (v Variant) sourceQuality() float64 {
+	if v.SourceQuality == 0 {
+		return 1.0
+	}
+
+	return v.SourceQuality
+}
+
+// String returns the variant description of v, in the form used by the
+// Alternates header.
+func (v Variant) String() string {
+	desc := `{"` + v.URI + `" ` + formatQ(v.sourceQuality())
+
+	if v.Type != "" {
+		desc += " {type " + v.Type + "}"
+	}
+	if v.Charset != "" {
+		desc += " {charset " + v.Charset + "}"
+	}
+	if v.Language != "" {
+		desc += " {language " + v.Language + "}"
+	}
+	if v.Length > 0 {
+		desc += " {length " + strconv.FormatInt(v.Length, 10) + "}"
+	}
+
+	return desc + "}"
+}
+
+// Alternates returns the value of the Alternates header which lists variants.
+func Alternates(variants []Variant) string {
+	descs := make([]string, len(variants))
+
+	for i, variant := range variants {
+		descs[i] = variant.String()
+	}
+
+	return strings.Join(descs, ", ")
+}
+
+// NegotiateDirectives represents the directives of the HTTP Negotiate header.
+type NegotiateDirectives struct {
+	// Trans indicates that the user agent supports transparent content
+	// negotiation.
+	Trans bool
+	// VList indicates that the user agent wants the variant list in every
+	// transparently negotiated response.
+	VList bool
+	// GuessSmall indicates that the user agent allows guessing responses
+	// which are small compared to a list response.
+	GuessSmall bool
+	// RVSA indicates that the user agent allows the server to run the
+	// remote variant selection algorithm version 1.0 on its behalf.
+	RVSA bool
+}
+
+// ParseNegotiate parses the value of the HTTP Negotiate header.
+func ParseNegotiate(val string) (d NegotiateDirectives) {
+	for _, directive := range strings.Split(formatHeaderVal(val), ",") {
+		switch directive {
+		case "trans":
+			d.Trans = true
+		case "vlist":
+			d.VList = true
+		case "guess-small":
+			d.GuessSmall = true
+		case "1.0":
+			d.RVSA = true
+		case "*":
+			d = NegotiateDirectives{Trans: true, VList: true, GuessSmall: true, RVSA: true}
+		}
+	}
+
+	return
+}
+
+// TCN represents the kind of a transparently negotiated response.
+type TCN int
+
+const (
+	// TCNNone is a response to a user agent which doesn't support
+	// transparent content negotiation.
+	TCNNone TCN = iota
+	// TCNList is a list response, which should be sent with the status
+	// 300 Multiple Choices.
+	TCNList
+	// TCNChoice is a choice response, which contains the chosen variant.
+	TCNChoice
+)
+
+// String returns the value of the TCN header for t.
+func (t TCN) String() string {
+	switch t {
+	case TCNList:
+		return "list"
+	case TCNChoice:
+		return "choice"
+	}
+
+	return ""
+}
+
+// SelectVariant runs the remote variant selection algorithm version 1.0
+// (RFC 2296) and returns the variant with the highest overall quality. q is
+// the overall quality of the variant, rounded to five decimal places. ok is
+// false if no variant is acceptable.
+func (n *Negotiator) SelectVariant(variants []Variant) (variant Variant, q float64, ok bool) {
+	for _, v := range variants {
+		overall := v.sourceQuality()
+
+		if v.Type != "" {
+			overall *= n.quality(headerAccept, v.Type)
+		}
+		if v.Charset != "" {
+			overall *= n.quality(headerAcceptCharset, v.Charset)
+		}
+		if v.Language != "" {
+			overall *= n.quality(headerAcceptLanguage, v.Language)
+		}
+
+		overall = math.Floor(overall*100000+0.5) / 100000
+
+		if overall > q {
+			variant, q, ok = v, overall, true
+		}
+	}
+
+	return
+}
+
+// Transparent negotiates variants according to the HTTP Negotiate header
+// (RFC 2295) and sets the TCN, Alternates, Vary and Content-Location headers
+// of the response in h.
+//
+// If the user agent doesn't support transparent content negotiation, the
+// variant is selected by the server and tcn is TCNNone. If the user agent
+// allows it, the server runs the remote variant selection algorithm and tcn
+// is TCNChoice. Otherwise, or if no variant is acceptable, tcn is TCNList and
+// the caller should respond with 300 Multiple Choices. ok reports whether
+// variant was chosen.
+func (n *Negotiator) Transparent(h http.Header, variants []Variant) (variant Variant, tcn TCN, ok bool) {
+	d := ParseNegotiate(n.header.Get(headerNegotiate))
+	variant, _, ok = n.SelectVariant(variants)
+
+	h.Add(headerVary, varyVariants(variants))
+
+	if !d.Trans && !d.VList && !d.GuessSmall && !d.RVSA {
+		return variant, TCNNone, ok
+	}
+
+	h.Set(headerAlternates, Alternates(variants))
+
+	if d.RVSA && ok {
+		tcn = TCNChoice
+		h.Set(headerContentLocation, variant.URI)
+	} else {
+		variant, tcn, ok = Variant{}, TCNList, false
+	}
+
+	h.Set(headerTCN, tcn.String())
+
+	return
+}
+
+func varyVariants(variants []Variant) string {
+	var hasType, hasCharset, hasLanguage bool
+
+	for _, v := range variants {
+		hasType = hasType || v.Type != ""
+		hasCharset = hasCharset || v.Charset != ""
+		hasLanguage = hasLanguage || v.Language != ""
+	}
+
+	vary := "negotiate"
+
+	if hasType {
+		vary += ", accept"
+	}
+	if hasCharset {
+		vary += ", accept-charset"
+	}
+	if hasLanguage {
+		vary += ", accept-language"
+	}
+
+	return vary
+}
+
+// formatQ formats q with at most three decimal places.
+func formatQ(q float64) string {
+	s := strconv.FormatFloat(q, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+
+	return strings.TrimSuffix(s, ".")
+}
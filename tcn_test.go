@@ -0,0 +1,109 @@
+package negotiator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var paperVariants = []Variant{
+	{URI: "paper.1", SourceQuality: 0.9, Type: "text/html", Language: "en"},
+	{URI: "paper.2", SourceQuality: 0.7, Type: "text/html", Language: "fr"},
+	{URI: "paper.3", SourceQuality: 1.0, Type: "application/postscript", Language: "en", Length: 53000},
+}
+
+func setUpTCNNegotiator(negotiate, accept, acceptLanguage string) *Negotiator {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(headerNegotiate, negotiate)
+	req.Header.Set(headerAccept, accept)
+	req.Header.Set(headerAcceptLanguage, acceptLanguage)
+
+	return New(req.Header)
+}
+
+type TCNSuite struct {
+	suite.Suite
+}
+
+func (s TCNSuite) TestAlternates() {
+	s.Equal(`{"paper.1" 0.9 {type text/html} {language en}}, `+
+		`{"paper.2" 0.7 {type text/html} {language fr}}, `+
+		`{"paper.3" 1 {type application/postscript} {language en} {length 53000}}`, Alternates(paperVariants))
+}
+
+func (s TCNSuite) TestParseNegotiate() {
+	s.Equal(NegotiateDirectives{Trans: true, VList: true}, ParseNegotiate("trans, vlist"))
+	s.Equal(NegotiateDirectives{RVSA: true}, ParseNegotiate("1.0"))
+	s.Equal(NegotiateDirectives{Trans: true, VList: true, GuessSmall: true, RVSA: true}, ParseNegotiate("*"))
+	s.Equal(NegotiateDirectives{}, ParseNegotiate(""))
+}
+
+func (s TCNSuite) TestSelectVariant() {
+	n := setUpTCNNegotiator("", "text/html, application/postscript;q=0.5", "fr, en;q=0.5")
+	variant, q, ok := n.SelectVariant(paperVariants)
+
+	s.True(ok)
+	s.Equal("paper.2", variant.URI)
+	s.Equal(0.7, q)
+}
+
+func (s TCNSuite) TestSelectVariantNotAcceptable() {
+	n := setUpTCNNegotiator("", "image/png", "")
+	_, _, ok := n.SelectVariant(paperVariants)
+
+	s.False(ok)
+}
+
+func (s TCNSuite) TestChoice() {
+	h := make(http.Header)
+	n := setUpTCNNegotiator("trans, 1.0", "text/html", "en")
+	variant, tcn, ok := n.Transparent(h, paperVariants)
+
+	s.True(ok)
+	s.Equal(TCNChoice, tcn)
+	s.Equal("paper.1", variant.URI)
+	s.Equal("choice", h.Get(headerTCN))
+	s.Equal("paper.1", h.Get(headerContentLocation))
+	s.Equal("negotiate, accept, accept-language", h.Get(headerVary))
+	s.Equal(Alternates(paperVariants), h.Get(headerAlternates))
+}
+
+func (s TCNSuite) TestList() {
+	h := make(http.Header)
+	n := setUpTCNNegotiator("trans, vlist", "text/html", "en")
+	_, tcn, ok := n.Transparent(h, paperVariants)
+
+	s.False(ok)
+	s.Equal(TCNList, tcn)
+	s.Equal("list", h.Get(headerTCN))
+	s.Equal("", h.Get(headerContentLocation))
+	s.Equal(Alternates(paperVariants), h.Get(headerAlternates))
+}
+
+func (s TCNSuite) TestListWhenNothingAcceptable() {
+	h := make(http.Header)
+	n := setUpTCNNegotiator("*", "image/png", "")
+	_, tcn, ok := n.Transparent(h, paperVariants)
+
+	s.False(ok)
+	s.Equal(TCNList, tcn)
+}
+
+func (s TCNSuite) TestNonTCNUserAgent() {
+	h := make(http.Header)
+	n := setUpTCNNegotiator("", "application/postscript", "")
+	variant, tcn, ok := n.Transparent(h, paperVariants)
+
+	s.True(ok)
+	s.Equal(TCNNone, tcn)
+	s.Equal("paper.3", variant.URI)
+	s.Equal("", h.Get(headerTCN))
+	s.Equal("", h.Get(headerAlternates))
+	s.Equal("negotiate, accept, accept-language", h.Get(headerVary))
+}
+
+func TestTCN(t *testing.T) {
+	suite.Run(t, new(TCNSuite))
+}
@@ -0,0 +1,50 @@
+package negotiator
+
+const (
+	headerTE = "TE"
+
+	teTrailers = "trailers"
+	teChunked  = "chunked"
+)
+
+// TransferCoding returns the most preferred transfer coding from the HTTP TE
+// header. As defined in RFC 9110, "trailers" is not a transfer coding and is
+// never returned, see AcceptsTrailers, and "chunked" is always acceptable to
+// HTTP/1.1 clients, which is assumed unless the Negotiator was created from a
+// request of another version. If nothing accepted, then empty string is
+// returned.
+func (n *Negotiator) TransferCoding(offers ...string) (bestOffer string) {
+	return n.decide(headerTE, n.parser(headerTE).selectOffer(offers, n.teSpecs()))
+}
+
+// AcceptsTrailers reports whether the HTTP TE header contains "trailers",
+// i.e. the client is willing to accept trailer fields in a chunked response.
+func (n *Negotiator) AcceptsTrailers() bool {
+	for _, spec := range n.specs(headerTE) {
+		if spec.val == teTrailers {
+			return true
+		}
+	}
+
+	return false
+}
+
+// teSpecs returns the transfer codings of the TE header, without "trailers"
+// and the wildcard of an absent header, and with "chunked" if acceptable.
+func (n *Negotiator) teSpecs() specs {
+	var ss specs
+	all := n.specs(headerTE)
+
+	for _, spec := range all {
+		if spec.val != teTrailers && spec.val != "*" && spec.val != teChunked {
+			ss = append(ss, spec)
+		}
+	}
+
+	if r := n.Request(); r == nil || (r.ProtoMajor == 1 && r.ProtoMinor >= 1) {
+		ss = append(ss, spec{val: teChunked, q: maxQ, index: len(all)})
+		ss.sort()
+	}
+
+	return ss
+}
@@ -0,0 +1,77 @@
+package negotiator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func setUpTERequest(proto, te string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Proto = proto
+	req.ProtoMajor, req.ProtoMinor, _ = http.ParseHTTPVersion(proto)
+	if te != "" {
+		req.Header.Set(headerTE, te)
+	}
+
+	return req
+}
+
+type TESuite struct {
+	suite.Suite
+}
+
+func (s TESuite) TestTransferCoding() {
+	n := setUpNegotiator(headerTE, "trailers, deflate;q=0.5, gzip;q=0.8")
+
+	s.Equal("gzip", n.TransferCoding("deflate", "gzip"))
+	s.Equal("chunked", n.TransferCoding("gzip", "chunked"))
+	s.Equal("", n.TransferCoding("compress"))
+	s.Equal("chunked", n.TransferCoding())
+
+	offer, ok := n.Decision(headerTE)
+	s.True(ok)
+	s.Equal("chunked", offer)
+}
+
+func (s TESuite) TestTrailers() {
+	n := setUpNegotiator(headerTE, "Trailers, deflate")
+
+	s.True(n.AcceptsTrailers())
+	s.Equal("", n.TransferCoding("trailers"))
+
+	s.False(setUpNegotiator(headerTE, "deflate").AcceptsTrailers())
+	s.False(New(nil).AcceptsTrailers())
+}
+
+func (s TESuite) TestAbsent() {
+	n := New(nil)
+
+	s.Equal("chunked", n.TransferCoding("gzip", "chunked"))
+	s.Equal("", n.TransferCoding("gzip"))
+}
+
+func (s TESuite) TestChunkedNotListed() {
+	n := setUpNegotiator(headerTE, "chunked;q=0")
+
+	s.Equal("chunked", n.TransferCoding("chunked"))
+}
+
+func (s TESuite) TestProtocols() {
+	n := NewFromRequest(setUpTERequest("HTTP/1.1", "gzip"))
+	s.Equal("chunked", n.TransferCoding("chunked", "gzip"))
+
+	n = NewFromRequest(setUpTERequest("HTTP/1.0", "gzip"))
+	s.Equal("gzip", n.TransferCoding("chunked", "gzip"))
+	s.Equal("", n.TransferCoding("chunked"))
+
+	n = NewFromRequest(setUpTERequest("HTTP/2.0", "trailers"))
+	s.Equal("", n.TransferCoding("chunked"))
+	s.True(n.AcceptsTrailers())
+}
+
+func TestTE(t *testing.T) {
+	suite.Run(t, new(TESuite))
+}
@@ -0,0 +1,38 @@
+package negotiator
+
+// TieBreak defines which offer wins when several offers have the same q.
+type TieBreak int
+
+const (
+	// TieBreakServer prefers the offer which comes first in the offers.
+	TieBreakServer TieBreak = iota
+	// TieBreakClient prefers the offer matched by the value which comes
+	// first in the header, and the offer which comes first in the offers
+	// among offers matched by the same value.
+	TieBreakClient
+	// TieBreakSpecificity prefers the offer matched by the more specific
+	// value, e.g. "text/html" over "text/*" over "*/*", and the offer which
+	// comes first in the offers among equally specific matches.
+	TieBreakSpecificity
+)
+
+// WithTieBreak sets the TieBreak of a Negotiator, which is TieBreakServer by
+// default. It applies to all the headers.
+func WithTieBreak(t TieBreak) Option {
+	return func(n *Negotiator) {
+		n.tieBreak = t
+	}
+}
+
+// prefers reports whether an offer matched by s wins over an offer with the
+// same q matched by best, which comes before it in the offers.
+func (t TieBreak) prefers(s, best spec) bool {
+	switch t {
+	case TieBreakClient:
+		return s.index < best.index
+	case TieBreakSpecificity:
+		return s.specificity() > best.specificity()
+	}
+
+	return false
+}
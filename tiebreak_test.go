@@ -0,0 +1,80 @@
+package negotiator
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func setUpTieBreakNegotiator(headerName, val string, t TieBreak) *Negotiator {
+	header := make(http.Header)
+	header.Set(headerName, val)
+
+	return New(header, WithTieBreak(t))
+}
+
+type TieBreakSuite struct {
+	suite.Suite
+}
+
+func (s TieBreakSuite) TestConcreteSortsFirst() {
+	n := setUpNegotiator(headerAccept, "*/*, text/*, text/html")
+	s.Equal("text/html", n.Type())
+
+	n = setUpNegotiator(headerAcceptLanguage, "*, en")
+	s.Equal("en", n.Language())
+}
+
+func (s TieBreakSuite) TestStableSort() {
+	n := setUpNegotiator(headerAcceptEncoding, "br;q=0.5, gzip, deflate;q=0.5, zstd")
+	specs := n.specs(headerAcceptEncoding)
+
+	s.Equal([]string{"gzip", "zstd", "br", "deflate"},
+		[]string{specs[0].val, specs[1].val, specs[2].val, specs[3].val})
+}
+
+func (s TieBreakSuite) TestServerOrder() {
+	s.Equal("de", setUpTieBreakNegotiator(headerAcceptLanguage, "en, de", TieBreakServer).Language("de", "en"))
+	s.Equal("application/json", setUpTieBreakNegotiator(headerAccept, "*/*, text/html", TieBreakServer).
+		Type("application/json", "text/html"))
+	s.Equal("br", setUpTieBreakNegotiator(headerAcceptEncoding, "gzip, *", TieBreakServer).Encoding("br", "gzip"))
+}
+
+func (s TieBreakSuite) TestClientOrder() {
+	s.Equal("en", setUpTieBreakNegotiator(headerAcceptLanguage, "en, de", TieBreakClient).Language("de", "en"))
+	s.Equal("application/json", setUpTieBreakNegotiator(headerAccept, "*/*, text/html", TieBreakClient).
+		Type("application/json", "text/html"))
+	s.Equal("text/html", setUpTieBreakNegotiator(headerAccept, "text/html, */*", TieBreakClient).
+		Type("application/json", "text/html"))
+	s.Equal("gzip", setUpTieBreakNegotiator(headerAcceptEncoding, "gzip, *", TieBreakClient).Encoding("br", "gzip"))
+	s.Equal("iso-8859-1", setUpTieBreakNegotiator(headerAcceptCharset, "iso-8859-1, utf-8", TieBreakClient).
+		Charset("utf-8", "iso-8859-1"))
+}
+
+func (s TieBreakSuite) TestSpecificity() {
+	s.Equal("de", setUpTieBreakNegotiator(headerAcceptLanguage, "en, de", TieBreakSpecificity).Language("de", "en"))
+	s.Equal("text/html", setUpTieBreakNegotiator(headerAccept, "*/*, text/*, text/html", TieBreakSpecificity).
+		Type("application/json", "text/plain", "text/html"))
+	s.Equal("text/plain", setUpTieBreakNegotiator(headerAccept, "*/*, text/*", TieBreakSpecificity).
+		Type("application/json", "text/plain"))
+	s.Equal("gzip", setUpTieBreakNegotiator(headerAcceptEncoding, "*, gzip", TieBreakSpecificity).Encoding("br", "gzip"))
+}
+
+func (s TieBreakSuite) TestOfferSet() {
+	set := NewOfferSet("application/json", "text/html")
+
+	s.Equal("application/json", setUpTieBreakNegotiator(headerAccept, "text/html, */*", TieBreakServer).SelectType(set))
+	s.Equal("text/html", setUpTieBreakNegotiator(headerAccept, "text/html, */*", TieBreakClient).SelectType(set))
+	s.Equal("text/html", setUpTieBreakNegotiator(headerAccept, "*/*, text/html", TieBreakSpecificity).SelectType(set))
+}
+
+func (s TieBreakSuite) TestLowerQNeverWins() {
+	n := setUpTieBreakNegotiator(headerAccept, "text/html;q=0.5, */*", TieBreakSpecificity)
+
+	s.Equal("application/json", n.Type("text/html", "application/json"))
+}
+
+func TestTieBreak(t *testing.T) {
+	suite.Run(t, new(TieBreakSuite))
+}
@@ -0,0 +1,183 @@
+package negotiator
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// VendorType represents a parsed vendor media type, such as
+// "application/vnd.acme.v2+json" or "application/vnd.acme+json; version=3".
+type VendorType struct {
+	// Type is the top-level type, e.g. "application".
+	Type string
+	// Vendor is the vendor tree name without the "vnd." prefix, e.g. "acme".
+	Vendor string
+	// Version is the requested version, or 0 if the media type is unversioned.
+	Version int
+	// Suffix is the structured syntax suffix without the "+", e.g. "json".
+	Suffix string
+}
+
+// ParseVendorType parses a vendor media type. The version is taken from a
+// "version" parameter if present, otherwise from a ".v<N>" tail of the vendor
+// tree. ok is false if mediaType is not in the vendor tree.
+func ParseVendorType(mediaType string) (vt VendorType, ok bool) {
+	parts := strings.Split(mediaType, ";")
+	typ := strings.ToLower(strings.TrimSpace(parts[0]))
+
+	slash := strings.Index(typ, "/")
+	if slash == -1 || !strings.HasPrefix(typ[slash+1:], "vnd.") {
+		return
+	}
+
+	vt.Type = typ[:slash]
+	tree := typ[slash+len("/vnd."):]
+
+	if plus := strings.LastIndex(tree, "+"); plus != -1 {
+		tree, vt.Suffix = tree[:plus], tree[plus+1:]
+	}
+
+	if dot := strings.LastIndex(tree, ".v"); dot != -1 {
+		if version, err := strconv.Atoi(tree[dot+2:]); err == nil && version > 0 {
+			tree, vt.Version = tree[:dot], version
+		}
+	}
+
+	if tree == "" {
+		return
+	}
+	vt.Vendor = tree
+
+	for _, param := range parts[1:] {
+		pair := strings.SplitN(strings.TrimSpace(param), "=", 2)
+
+		if len(pair) == 2 && strings.ToLower(pair[0]) == "version" {
+			if version, err := strconv.Atoi(strings.Trim(pair[1], `"`)); err == nil && version > 0 {
+				vt.Version = version
+			}
+		}
+	}
+
+	return vt, true
+}
+
+// Versioning describes the versions of a vendor media type which an API
+// supports.
+type Versioning struct {
+	// Type is the top-level type of the vendor media type, "application" if
+	// empty.
+	Type string
+	// Vendor is the vendor tree name, e.g. "acme" for "application/vnd.acme+json".
+	Vendor string
+	// Suffix is the structured syntax suffix, e.g. "json". If set, vendor
+	// types with a different suffix are ignored and the client accepting
+	// "application/<Suffix>" selects Default.
+	Suffix string
+	// Versions lists the supported versions.
+	Versions []int
+	// Default is the version selected when the client only accepts the
+	// generic media type, e.g. "application/json" or "*/*".
+	Default int
+}
+
+func (v Versioning) supports(version int) bool {
+	for _, supported := range v.Versions {
+		if supported == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Version returns the version from v.Versions which the client prefers via a
+// vendor media type in the HTTP Accept header: the one with the highest q,
+// and the highest version among equal q. A versioned media type such as
+// "application/vnd.acme.v2+json" takes precedence over an unversioned one for
+// its version. If the client doesn't ask for the vendor type, or prefers the
+// generic media type, then v.Default is returned, unless a versioned media
+// type excludes it with q=0. ok is false if nothing accepted. The header is parsed with the limits and strictness of n.
+func (n *Negotiator) Version(v Versioning) (version int, ok bool) {
+	var bestQ qvalue
+
+	for _, supported := range v.Versions {
+		if q, _ := v.quality(n.specs(headerAccept), supported); q > bestQ || (q == bestQ && q > 0 && supported > version) {
+			version, bestQ = supported, q
+		}
+	}
+
+	generic := "*/*"
+	if v.Suffix != "" {
+		generic = v.topLevelType() + "/" + strings.ToLower(v.Suffix)
+	}
+
+	if q := n.parser(headerAccept).quality(generic, n.specs(headerAccept)); q > bestQ && v.supports(v.Default) {
+		if defaultQ, versioned := v.quality(n.specs(headerAccept), v.Default); defaultQ > 0 || !versioned {
+			return v.Default, true
+		}
+	}
+
+	return version, bestQ > 0
+}
+
+// quality returns the q of version in specs: the q of the most specific
+// matching vendor media range, where a versioned range is more specific than
+// an unversioned one. versioned reports whether a versioned range matched.
+func (v Versioning) quality(specs specs, version int) (q qvalue, versioned bool) {
+	best := -1
+
+	for _, s := range specs {
+		vt, isVendor := ParseVendorType(s.val + ";" + s.params)
+		if !isVendor || vt.Type != v.topLevelType() || vt.Vendor != strings.ToLower(v.Vendor) {
+			continue
+		}
+		if v.Suffix != "" && vt.Suffix != "" && vt.Suffix != strings.ToLower(v.Suffix) {
+			continue
+		}
+
+		specificity := 0
+		if vt.Version == version {
+			specificity = 1
+		} else if vt.Version != 0 {
+			continue
+		}
+
+		if specificity > best || (specificity == best && s.q > q) {
+			best, q = specificity, s.q
+		}
+	}
+
+	return q, best == 1
+}
+
+func (v Versioning) topLevelType() string {
+	if v.Type == "" {
+		return "application"
+	}
+
+	return strings.ToLower(v.Type)
+}
+
+// VersionHandler returns a handler which negotiates the API version for each
+// request and stores it in the request context, where it can be retrieved
+// with VersionFromContext. If nothing accepted, it responds with
+// 406 Not Acceptable.
+func VersionHandler(v Versioning, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, ok := FromRequest(r).Version(v)
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+			return
+		}
+
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), versionKey, version)))
+	})
+}
+
+// VersionFromContext returns the API version selected by VersionHandler.
+func VersionFromContext(ctx context.Context) (version int, ok bool) {
+	version, ok = ctx.Value(versionKey).(int)
+	return
+}
@@ -0,0 +1,194 @@
+package negotiator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var acmeVersioning = Versioning{
+	Vendor:   "acme",
+	Suffix:   "json",
+	Versions: []int{1, 2, 3},
+	Default:  2,
+}
+
+type VersionSuite struct {
+	suite.Suite
+}
+
+func (s VersionSuite) TestParseVersionInTree() {
+	vt, ok := ParseVendorType("application/vnd.acme.v2+json")
+
+	s.True(ok)
+	s.Equal(VendorType{Type: "application", Vendor: "acme", Version: 2, Suffix: "json"}, vt)
+}
+
+func (s VersionSuite) TestParseVersionParam() {
+	vt, ok := ParseVendorType(`application/vnd.acme+json; version="3"`)
+
+	s.True(ok)
+	s.Equal(VendorType{Type: "application", Vendor: "acme", Version: 3, Suffix: "json"}, vt)
+}
+
+func (s VersionSuite) TestParseDottedTree() {
+	vt, ok := ParseVendorType("application/vnd.acme.video")
+
+	s.True(ok)
+	s.Equal(VendorType{Type: "application", Vendor: "acme.video"}, vt)
+}
+
+func (s VersionSuite) TestParseNotVendor() {
+	_, ok := ParseVendorType("application/json")
+	s.False(ok)
+}
+
+func (s VersionSuite) TestHighestAccepted() {
+	n := setUpNegotiator(headerAccept, "application/vnd.acme.v1+json, application/vnd.acme+json; version=3")
+	version, ok := n.Version(acmeVersioning)
+
+	s.True(ok)
+	s.Equal(3, version)
+}
+
+func (s VersionSuite) TestUnsupportedVersion() {
+	n := setUpNegotiator(headerAccept, "application/vnd.acme.v9+json")
+	_, ok := n.Version(acmeVersioning)
+
+	s.False(ok)
+}
+
+func (s VersionSuite) TestUnversionedSelectsHighest() {
+	n := setUpNegotiator(headerAccept, "application/vnd.acme+json")
+	version, ok := n.Version(acmeVersioning)
+
+	s.True(ok)
+	s.Equal(3, version)
+}
+
+func (s VersionSuite) TestQZeroExcluded() {
+	n := setUpNegotiator(headerAccept, "application/vnd.acme.v3+json;q=0, application/vnd.acme.v1+json")
+	version, ok := n.Version(acmeVersioning)
+
+	s.True(ok)
+	s.Equal(1, version)
+}
+
+func (s VersionSuite) TestOtherSuffix() {
+	n := setUpNegotiator(headerAccept, "application/vnd.acme.v3+xml")
+	_, ok := n.Version(acmeVersioning)
+
+	s.False(ok)
+}
+
+func (s VersionSuite) TestDefault() {
+	n := setUpNegotiator(headerAccept, "application/json")
+	version, ok := n.Version(acmeVersioning)
+
+	s.True(ok)
+	s.Equal(2, version)
+}
+
+func (s VersionSuite) TestDefaultExcluded() {
+	n := setUpNegotiator(headerAccept, "application/vnd.acme.v2+json;q=0, application/json")
+	_, ok := n.Version(acmeVersioning)
+
+	s.False(ok)
+
+	n = setUpNegotiator(headerAccept, "application/vnd.acme.v2+json;q=0, application/vnd.acme+json;q=0.5, application/json")
+	version, ok := n.Version(acmeVersioning)
+
+	s.True(ok)
+	s.Equal(3, version)
+}
+
+func (s VersionSuite) TestDefaultWithoutAccept() {
+	n := setUpNegotiator(headerAccept, "")
+	version, ok := n.Version(acmeVersioning)
+
+	s.True(ok)
+	s.Equal(2, version)
+}
+
+func (s VersionSuite) TestQRanking() {
+	n := setUpNegotiator(headerAccept, "application/vnd.acme.v3+json;q=0.5, application/vnd.acme.v1+json;q=0.9, application/vnd.acme+json;q=0.1")
+	version, ok := n.Version(acmeVersioning)
+
+	s.True(ok)
+	s.Equal(1, version)
+
+	n = setUpNegotiator(headerAccept, "application/vnd.acme.v1+json;q=0.2, application/json")
+	version, ok = n.Version(acmeVersioning)
+
+	s.True(ok)
+	s.Equal(2, version)
+}
+
+func (s VersionSuite) TestOtherTopLevelType() {
+	n := setUpNegotiator(headerAccept, "text/vnd.acme.v3+json")
+	_, ok := n.Version(acmeVersioning)
+
+	s.False(ok)
+
+	v := acmeVersioning
+	v.Type = "Text"
+	version, ok := n.Version(v)
+
+	s.True(ok)
+	s.Equal(3, version)
+}
+
+func (s VersionSuite) TestLimitsAndStrict() {
+	header := make(http.Header)
+	header.Set(headerAccept, "application/vnd.acme.v1+json, application/vnd.acme.v3+json")
+
+	version, ok := New(header, WithLimits(Limits{MaxElements: 1})).Version(acmeVersioning)
+	s.True(ok)
+	s.Equal(1, version)
+
+	header.Set(headerAccept, "application/vnd.acme.v1+json, application")
+
+	_, ok = New(header, WithStrict()).Version(acmeVersioning)
+	s.False(ok)
+}
+
+func (s VersionSuite) TestNotRecorded() {
+	n := setUpNegotiator(headerAccept, "application/json")
+	n.Version(acmeVersioning)
+
+	_, ok := n.Decision(headerAccept)
+	s.False(ok)
+}
+
+func (s VersionSuite) TestHandler() {
+	var version int
+
+	h := VersionHandler(acmeVersioning, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, _ = VersionFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(headerAccept, "application/vnd.acme.v1+json")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(1, version)
+}
+
+func (s VersionSuite) TestHandlerNotAcceptable() {
+	h := VersionHandler(acmeVersioning, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(headerAccept, "text/html")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	s.Equal(http.StatusNotAcceptable, res.Code)
+}
+
+func TestVersion(t *testing.T) {
+	suite.Run(t, new(VersionSuite))
+}